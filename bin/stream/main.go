@@ -0,0 +1,16 @@
+package main
+
+import (
+	"net/http"
+
+	handler "go_lambdas/pkg/handlers"
+
+	"github.com/aws/aws-lambda-go/lambdaurl"
+)
+
+// main starts the RESPONSE_MODE=stream entrypoint for a Lambda Function URL
+// with response streaming enabled, as an alternative to bin/main.go's
+// buffered lambda.Start(handler.Handler).
+func main() {
+	lambdaurl.Start(http.HandlerFunc(handler.StreamHandler))
+}
@@ -0,0 +1,13 @@
+package main
+
+import (
+	"net/http"
+
+	handler "go_lambdas/pkg/handlers"
+
+	"github.com/fnproject/fdk-go"
+)
+
+func main() {
+	fdk.Handle(fdk.HTTPHandler(http.HandlerFunc(handler.FnHandler)))
+}
@@ -0,0 +1,24 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"os"
+
+	"go_lambdas/pkg/storage"
+)
+
+// main runs the one-shot migration of legacy file-script items (written
+// without a pk attribute) into the pk/timestamp-keyed schema that
+// LatestRecord, ListHashHistory, and GetByHash query against.
+func main() {
+	table := flag.String("table", "file-script", "DynamoDB table to migrate legacy items in")
+	flag.Parse()
+
+	migrated, err := storage.MigrateLegacyDynamoItems(*table)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "migration failed: %s\n", err.Error())
+		os.Exit(1)
+	}
+	fmt.Printf("migrated %d legacy item(s) in table %s\n", migrated, *table)
+}
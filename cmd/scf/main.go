@@ -0,0 +1,11 @@
+package main
+
+import (
+	handler "go_lambdas/pkg/handlers"
+
+	"github.com/tencentyun/scf-go-lib/cloudfunction"
+)
+
+func main() {
+	cloudfunction.Start(handler.SCFHandler)
+}
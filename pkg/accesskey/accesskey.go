@@ -0,0 +1,348 @@
+// Package accesskey implements the access-key subsystem that gates the
+// Lambda endpoint: minting, listing, revoking, and verifying the
+// key/secret pairs stored in the halogen-access-keys DynamoDB table.
+package accesskey
+
+import (
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"fmt"
+	"net/url"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"go_lambdas/pkg/functions"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/session"
+	"github.com/aws/aws-sdk-go/service/dynamodb"
+	"github.com/aws/aws-sdk-go/service/dynamodb/dynamodbattribute"
+)
+
+const (
+	tableName                 = "halogen-access-keys"
+	defaultRateLimitPerMinute = 60
+	sigV4Scheme               = "HALOGEN-HMAC-SHA256"
+	maxClockSkew              = 5 * time.Minute
+)
+
+// AccessKey represents a record in the halogen-access-keys DynamoDB table.
+// Secret is stored in plaintext (not hashed) because Verify must recompute
+// HMAC signatures against it, the same tradeoff AWS itself makes for IAM
+// secret access keys; access to the table is restricted via IAM instead.
+type AccessKey struct {
+	KeyID              string `json:"keyId" dynamodbav:"KeyID"`
+	Secret             string `json:"-" dynamodbav:"Secret"`
+	CreatedAt          string `json:"createdAt" dynamodbav:"CreatedAt"`
+	ExpiresAt          string `json:"expiresAt,omitempty" dynamodbav:"ExpiresAt,omitempty"`
+	Revoked            bool   `json:"revoked" dynamodbav:"Revoked"`
+	RateLimitPerMinute int    `json:"rateLimitPerMinute" dynamodbav:"RateLimitPerMinute"`
+}
+
+// Generate mints a new access key/secret pair, persists it to DynamoDB, and
+// returns the key ID and plaintext secret. The secret is only ever
+// available here at mint time; callers must store it themselves.
+func Generate(ttl time.Duration, rateLimitPerMinute int) (keyID, secret string, err error) {
+	keyID, err = randomToken(9)
+	if err != nil {
+		return "", "", fmt.Errorf("failed to generate key id: %w", err)
+	}
+	secret, err = randomToken(32)
+	if err != nil {
+		return "", "", fmt.Errorf("failed to generate secret: %w", err)
+	}
+
+	if rateLimitPerMinute <= 0 {
+		rateLimitPerMinute = defaultRateLimitPerMinute
+	}
+
+	var expiresAt string
+	if ttl > 0 {
+		expiresAt = time.Now().Add(ttl).UTC().Format(time.RFC3339)
+	}
+
+	record := AccessKey{
+		KeyID:              keyID,
+		Secret:             secret,
+		CreatedAt:          time.Now().UTC().Format(time.RFC3339),
+		ExpiresAt:          expiresAt,
+		Revoked:            false,
+		RateLimitPerMinute: rateLimitPerMinute,
+	}
+
+	svc, err := dynamoClient()
+	if err != nil {
+		return "", "", err
+	}
+
+	item, err := dynamodbattribute.MarshalMap(record)
+	if err != nil {
+		return "", "", fmt.Errorf("failed to marshal access key: %w", err)
+	}
+
+	_, err = svc.PutItem(&dynamodb.PutItemInput{
+		TableName: aws.String(tableName),
+		Item:      item,
+	})
+	if err != nil {
+		return "", "", fmt.Errorf("failed to persist access key: %w", err)
+	}
+
+	return keyID, secret, nil
+}
+
+// List returns every access key record, for administrative tooling. The
+// Secret field is never serialized (json:"-"), so it is safe to expose the
+// result directly.
+func List() ([]AccessKey, error) {
+	svc, err := dynamoClient()
+	if err != nil {
+		return nil, err
+	}
+
+	input := &dynamodb.ScanInput{TableName: aws.String(tableName)}
+	var keys []AccessKey
+	for {
+		result, err := svc.Scan(input)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan access keys table: %w", err)
+		}
+
+		var page []AccessKey
+		if err := dynamodbattribute.UnmarshalListOfMaps(result.Items, &page); err != nil {
+			return nil, fmt.Errorf("failed to unmarshal access keys: %w", err)
+		}
+		keys = append(keys, page...)
+
+		if len(result.LastEvaluatedKey) == 0 {
+			return keys, nil
+		}
+		input.ExclusiveStartKey = result.LastEvaluatedKey
+	}
+}
+
+// Revoke marks an access key as revoked so Verify rejects it from then on.
+func Revoke(keyID string) error {
+	svc, err := dynamoClient()
+	if err != nil {
+		return err
+	}
+
+	_, err = svc.UpdateItem(&dynamodb.UpdateItemInput{
+		TableName: aws.String(tableName),
+		Key: map[string]*dynamodb.AttributeValue{
+			"KeyID": {S: aws.String(keyID)},
+		},
+		UpdateExpression: aws.String("SET Revoked = :true"),
+		ExpressionAttributeValues: map[string]*dynamodb.AttributeValue{
+			":true": {BOOL: aws.Bool(true)},
+		},
+	})
+	if err != nil {
+		return fmt.Errorf("failed to revoke access key %s: %w", keyID, err)
+	}
+	return nil
+}
+
+// Verify authenticates an incoming API Gateway request against the
+// access-key table and, on success, enforces that key's per-minute rate
+// limit. It accepts two schemes:
+//
+//   - X-Api-Key: <keyId>.<secret> — a direct, simple credential.
+//   - Authorization: HALOGEN-HMAC-SHA256 Credential=<keyId>, Signature=<hex>
+//     alongside an X-Halogen-Date header — a SigV4-style signature over the
+//     request method, path, canonical query string, and date, proving possession of the secret
+//     without sending it on the wire.
+//
+// It returns the authenticated key ID on success.
+func Verify(req *functions.Request) (keyID string, err error) {
+	if apiKey := req.HeaderValue("X-Api-Key"); apiKey != "" {
+		return verifyAPIKey(apiKey)
+	}
+	if auth := req.HeaderValue("Authorization"); auth != "" {
+		return verifySignature(req, auth)
+	}
+	return "", fmt.Errorf("missing X-Api-Key or Authorization header")
+}
+
+func verifyAPIKey(apiKey string) (string, error) {
+	parts := strings.SplitN(apiKey, ".", 2)
+	if len(parts) != 2 {
+		return "", fmt.Errorf("malformed X-Api-Key header")
+	}
+	keyID, secret := parts[0], parts[1]
+
+	record, err := lookupAndValidate(keyID)
+	if err != nil {
+		return "", err
+	}
+	if !hmac.Equal([]byte(secret), []byte(record.Secret)) {
+		return "", fmt.Errorf("invalid access key secret")
+	}
+	if err := checkRateLimit(keyID, record.RateLimitPerMinute); err != nil {
+		return "", err
+	}
+	return keyID, nil
+}
+
+func verifySignature(req *functions.Request, authHeader string) (string, error) {
+	if !strings.HasPrefix(authHeader, sigV4Scheme+" ") {
+		return "", fmt.Errorf("unsupported authorization scheme")
+	}
+	params := parseAuthParams(strings.TrimPrefix(authHeader, sigV4Scheme+" "))
+	keyID, signature := params["Credential"], params["Signature"]
+	if keyID == "" || signature == "" {
+		return "", fmt.Errorf("malformed Authorization header")
+	}
+
+	dateHeader := req.HeaderValue("X-Halogen-Date")
+	if dateHeader == "" {
+		return "", fmt.Errorf("missing X-Halogen-Date header")
+	}
+	requestTime, err := time.Parse(time.RFC3339, dateHeader)
+	if err != nil {
+		return "", fmt.Errorf("malformed X-Halogen-Date header: %w", err)
+	}
+	if skew := time.Since(requestTime); skew > maxClockSkew || skew < -maxClockSkew {
+		return "", fmt.Errorf("request timestamp outside allowed clock skew")
+	}
+
+	record, err := lookupAndValidate(keyID)
+	if err != nil {
+		return "", err
+	}
+
+	stringToSign := strings.Join([]string{req.HTTPMethod, req.Path, canonicalQueryString(req.QueryStringParameters), dateHeader}, "\n")
+	mac := hmac.New(sha256.New, []byte(record.Secret))
+	mac.Write([]byte(stringToSign))
+	expected := hex.EncodeToString(mac.Sum(nil))
+	if !hmac.Equal([]byte(signature), []byte(expected)) {
+		return "", fmt.Errorf("signature mismatch")
+	}
+
+	if err := checkRateLimit(keyID, record.RateLimitPerMinute); err != nil {
+		return "", err
+	}
+	return keyID, nil
+}
+
+// canonicalQueryString builds a deterministic, signable representation of a
+// request's query parameters, sorted by key. Without this, a signature
+// computed for one query string (e.g. one `hash=` value) would validate for
+// any other query string on the same method+path within the clock-skew
+// window.
+func canonicalQueryString(params map[string]string) string {
+	keys := make([]string, 0, len(params))
+	for k := range params {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+
+	pairs := make([]string, len(keys))
+	for i, k := range keys {
+		pairs[i] = url.QueryEscape(k) + "=" + url.QueryEscape(params[k])
+	}
+	return strings.Join(pairs, "&")
+}
+
+// lookupAndValidate fetches an access key by ID and rejects it if it's
+// unknown, revoked, or past its expiry.
+func lookupAndValidate(keyID string) (AccessKey, error) {
+	svc, err := dynamoClient()
+	if err != nil {
+		return AccessKey{}, err
+	}
+
+	result, err := svc.GetItem(&dynamodb.GetItemInput{
+		TableName: aws.String(tableName),
+		Key: map[string]*dynamodb.AttributeValue{
+			"KeyID": {S: aws.String(keyID)},
+		},
+	})
+	if err != nil {
+		return AccessKey{}, fmt.Errorf("failed to look up access key: %w", err)
+	}
+	if result.Item == nil {
+		return AccessKey{}, fmt.Errorf("unknown access key")
+	}
+
+	var record AccessKey
+	if err := dynamodbattribute.UnmarshalMap(result.Item, &record); err != nil {
+		return AccessKey{}, fmt.Errorf("failed to unmarshal access key: %w", err)
+	}
+	if record.Revoked {
+		return AccessKey{}, fmt.Errorf("access key has been revoked")
+	}
+	if record.ExpiresAt != "" {
+		expiresAt, err := time.Parse(time.RFC3339, record.ExpiresAt)
+		if err == nil && time.Now().After(expiresAt) {
+			return AccessKey{}, fmt.Errorf("access key has expired")
+		}
+	}
+	return record, nil
+}
+
+// rateWindow tracks a fixed one-minute request count for a single key.
+// Being process-local, it only bounds request rate per warm Lambda
+// container, not globally across concurrent containers.
+type rateWindow struct {
+	windowStart time.Time
+	count       int
+}
+
+var rateLimiter = struct {
+	mu      sync.Mutex
+	windows map[string]*rateWindow
+}{windows: make(map[string]*rateWindow)}
+
+func checkRateLimit(keyID string, limitPerMinute int) error {
+	rateLimiter.mu.Lock()
+	defer rateLimiter.mu.Unlock()
+
+	now := time.Now()
+	w, ok := rateLimiter.windows[keyID]
+	if !ok || now.Sub(w.windowStart) >= time.Minute {
+		w = &rateWindow{windowStart: now}
+		rateLimiter.windows[keyID] = w
+	}
+	w.count++
+	if w.count > limitPerMinute {
+		return fmt.Errorf("rate limit of %d requests/minute exceeded for key %s", limitPerMinute, keyID)
+	}
+	return nil
+}
+
+func dynamoClient() (*dynamodb.DynamoDB, error) {
+	sess, err := session.NewSession()
+	if err != nil {
+		return nil, fmt.Errorf("failed to create AWS session: %w", err)
+	}
+	return dynamodb.New(sess), nil
+}
+
+// randomToken returns a URL-safe base64 string encoding n random bytes.
+func randomToken(n int) (string, error) {
+	buf := make([]byte, n)
+	if _, err := rand.Read(buf); err != nil {
+		return "", fmt.Errorf("failed to read random bytes: %w", err)
+	}
+	return base64.RawURLEncoding.EncodeToString(buf), nil
+}
+
+// parseAuthParams parses a "Key1=val1, Key2=val2" parameter list, as found
+// after the scheme name in the Authorization header.
+func parseAuthParams(s string) map[string]string {
+	params := make(map[string]string)
+	for _, part := range strings.Split(s, ",") {
+		kv := strings.SplitN(strings.TrimSpace(part), "=", 2)
+		if len(kv) == 2 {
+			params[kv[0]] = kv[1]
+		}
+	}
+	return params
+}
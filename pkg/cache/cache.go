@@ -0,0 +1,127 @@
+// Package cache provides a small process-level TTL+LRU cache. It exists to
+// let warm Lambda containers skip repeated DynamoDB/S3 round-trips for data
+// that changes infrequently; a cold container always starts empty, so
+// correctness never depends on anything actually being cached.
+package cache
+
+import (
+	"sync"
+	"time"
+)
+
+// Stats holds the hit/miss counters for a Cache, exposed via the ?op=stats
+// route so cold vs. warm invocation behavior is observable.
+type Stats struct {
+	Hits   uint64 `json:"hits"`
+	Misses uint64 `json:"misses"`
+}
+
+type entry[V any] struct {
+	value     V
+	size      int64
+	expiresAt time.Time
+}
+
+// Cache is a TTL+LRU cache of values of type V. Entries expire after their
+// TTL and are evicted oldest-first once capacity or maxBytes is exceeded.
+type Cache[V any] struct {
+	mu        sync.Mutex
+	capacity  int
+	maxBytes  int64
+	usedBytes int64
+	order     []string
+	entries   map[string]*entry[V]
+	hits      uint64
+	misses    uint64
+}
+
+// New returns a Cache holding at most capacity entries (0 means unlimited)
+// and at most maxBytes of total size across entries stored via PutSized (0
+// means unlimited; Put entries have size 0 and never count against it).
+// The most recently stored entry is never evicted by its own insertion, so a
+// single entry larger than maxBytes is still kept, alone.
+func New[V any](capacity int, maxBytes int64) *Cache[V] {
+	return &Cache[V]{
+		capacity: capacity,
+		maxBytes: maxBytes,
+		entries:  make(map[string]*entry[V]),
+	}
+}
+
+// Get returns the value stored under key, if present and not yet expired.
+func (c *Cache[V]) Get(key string) (V, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	e, ok := c.entries[key]
+	if !ok || time.Now().After(e.expiresAt) {
+		if ok {
+			c.removeLocked(key)
+		}
+		c.misses++
+		var zero V
+		return zero, false
+	}
+	c.touchLocked(key)
+	c.hits++
+	return e.value, true
+}
+
+// Put stores value under key for ttl, with no byte-size accounting.
+func (c *Cache[V]) Put(key string, value V, ttl time.Duration) {
+	c.PutSized(key, value, 0, ttl)
+}
+
+// PutSized stores value under key for ttl, counting size bytes against
+// maxBytes alongside the LRU capacity.
+func (c *Cache[V]) PutSized(key string, value V, size int64, ttl time.Duration) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if _, exists := c.entries[key]; exists {
+		c.removeLocked(key)
+	}
+	c.order = append(c.order, key)
+	c.entries[key] = &entry[V]{value: value, size: size, expiresAt: time.Now().Add(ttl)}
+	c.usedBytes += size
+
+	for len(c.order) > 1 && c.overCapacityLocked() {
+		c.removeLocked(c.order[0])
+	}
+}
+
+func (c *Cache[V]) overCapacityLocked() bool {
+	return (c.capacity > 0 && len(c.order) > c.capacity) || (c.maxBytes > 0 && c.usedBytes > c.maxBytes)
+}
+
+// touchLocked marks key as most-recently-used. Must be called with c.mu held.
+func (c *Cache[V]) touchLocked(key string) {
+	for i, k := range c.order {
+		if k == key {
+			c.order = append(c.order[:i], c.order[i+1:]...)
+			c.order = append(c.order, key)
+			return
+		}
+	}
+}
+
+// removeLocked deletes key from the cache. Must be called with c.mu held.
+func (c *Cache[V]) removeLocked(key string) {
+	if e, ok := c.entries[key]; ok {
+		c.usedBytes -= e.size
+		delete(c.entries, key)
+	}
+	for i, k := range c.order {
+		if k == key {
+			c.order = append(c.order[:i], c.order[i+1:]...)
+			return
+		}
+	}
+}
+
+// Stats returns the current hit/miss counters.
+func (c *Cache[V]) Stats() Stats {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return Stats{Hits: c.hits, Misses: c.misses}
+}
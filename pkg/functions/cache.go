@@ -0,0 +1,112 @@
+package functions
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"os"
+	"strconv"
+	"time"
+
+	"go_lambdas/pkg/cache"
+	"go_lambdas/pkg/storage"
+)
+
+const (
+	defaultLatestHashTTL       = 10 * time.Second
+	defaultObjectCacheMaxBytes = 64 * 1024 * 1024 // 64MiB
+	objectCacheCapacity        = 32
+	zipFolderCacheCapacity     = 64
+	// objectCacheTTL is long relative to latestHashTTL because staleness is
+	// actually caught by the ETag check in cachedGetObject, not by expiry.
+	objectCacheTTL = time.Hour
+)
+
+// latestHashValue is what latestHashCache stores: the single most recent
+// hash/filename pair, cached for latestHashTTL so warm invocations skip the
+// metadata store Query.
+type latestHashValue struct {
+	Hash     string
+	Filename string
+}
+
+var (
+	latestHashCache = cache.New[latestHashValue](1, 0)
+	zipFolderCache  = cache.New[string](zipFolderCacheCapacity, 0)
+	objectCache     = cache.New[[]byte](objectCacheCapacity, objectCacheMaxBytes())
+)
+
+// latestHashTTL returns the configurable lifetime of the cached latest-hash
+// lookup.
+func latestHashTTL() time.Duration {
+	if v := os.Getenv("LATEST_HASH_TTL_SECONDS"); v != "" {
+		if n, err := strconv.Atoi(v); err == nil && n > 0 {
+			return time.Duration(n) * time.Second
+		}
+	}
+	return defaultLatestHashTTL
+}
+
+// objectCacheMaxBytes returns the configurable total size budget for cached
+// S3 object bytes.
+func objectCacheMaxBytes() int64 {
+	if v := os.Getenv("CACHE_MAX_BYTES"); v != "" {
+		if n, err := strconv.ParseInt(v, 10, 64); err == nil && n > 0 {
+			return n
+		}
+	}
+	return defaultObjectCacheMaxBytes
+}
+
+// cachedGetObject fetches an object's bytes, serving them from objectCache
+// when the object's current ETag (from a cheap HeadObject) matches what's
+// cached, and fetching + populating the cache otherwise.
+func cachedGetObject(objectStore storage.ObjectStore, bucket, key string) ([]byte, error) {
+	_, etag, err := objectStore.Head(bucket, key)
+	if err != nil {
+		return nil, fmt.Errorf("failed to head object %s: %w", key, err)
+	}
+
+	cacheKey := bucket + "/" + key + "#" + etag
+	if content, ok := objectCache.Get(cacheKey); ok {
+		return content, nil
+	}
+
+	content, err := objectStore.Get(bucket, key)
+	if err != nil {
+		return nil, err
+	}
+	objectCache.PutSized(cacheKey, content, int64(len(content)), objectCacheTTL)
+	return content, nil
+}
+
+// cacheStats is the JSON shape returned by GetCacheStats.
+type cacheStats struct {
+	LatestHash cache.Stats `json:"latestHash"`
+	ZipFolder  cache.Stats `json:"zipFolder"`
+	Object     cache.Stats `json:"object"`
+}
+
+// GetCacheStats serves ?op=stats, reporting hit/miss counters for the
+// process-level caches consulted by GetLatestHashFilePair,
+// getZipFileFromFolder, and the base64 zip download path. No single hash is
+// involved in serving it, so its first return value is always "".
+func GetCacheStats(req *Request) (hash string, resp *Response, err error) {
+	body, err := json.Marshal(cacheStats{
+		LatestHash: latestHashCache.Stats(),
+		ZipFolder:  zipFolderCache.Stats(),
+		Object:     objectCache.Stats(),
+	})
+	if err != nil {
+		return "", &Response{
+			StatusCode: http.StatusInternalServerError,
+			Body:       "Error encoding cache stats: " + err.Error(),
+		}, nil
+	}
+
+	return "", &Response{
+		StatusCode: http.StatusOK,
+		Body:       string(body),
+		Headers:    map[string]string{"Content-Type": "application/json"},
+	}, nil
+}
@@ -1,22 +1,16 @@
 package functions
 
 import (
-	"bytes"
 	"encoding/base64"
+	"encoding/json"
 	"fmt"
-	"io"
 	"net/http"
 	"os"
-	"sort"
+	"strconv"
 	"strings"
 	"time"
 
-	"github.com/aws/aws-lambda-go/events"
-	"github.com/aws/aws-sdk-go/aws"
-	"github.com/aws/aws-sdk-go/aws/session"
-	"github.com/aws/aws-sdk-go/service/dynamodb"
-	"github.com/aws/aws-sdk-go/service/dynamodb/dynamodbattribute"
-	"github.com/aws/aws-sdk-go/service/s3"
+	"go_lambdas/pkg/storage"
 )
 
 // Item represents a record in DynamoDB
@@ -26,165 +20,320 @@ type Item struct {
 	Timestamp string `json:"timestamp"`
 }
 
-// GetLatestHashFilePairAndZip returns the latest hash and filename pair from DynamoDB and fetches the zip file from S3
-func GetLatestHashFilePairAndZip(req *events.APIGatewayProxyRequest) (*events.APIGatewayProxyResponse, error) {
-	// Get bucket name from environment variable
-	bucketName := os.Getenv("bucketName")
-	if bucketName == "" {
-		errMessage := "S3 bucket name is not set"
-		fmt.Println(errMessage)
-		return &events.APIGatewayProxyResponse{
-			StatusCode: http.StatusInternalServerError,
-			Body:       errMessage,
-		}, nil
-	}
+const (
+	defaultPresignThresholdBytes = 4 * 1024 * 1024 // base64-encodes to ~5.3MiB, just under API Gateway/Lambda's 6MB payload cap
+	defaultPresignTTL            = 5 * time.Minute
+)
+
+// presignMetadata is the JSON body returned alongside a 302 redirect in
+// RESPONSE_MODE=presign, so a client can verify what it's about to download
+// before following the Location header.
+type presignMetadata struct {
+	Hash     string `json:"hash"`
+	Filename string `json:"filename"`
+	Size     int64  `json:"size"`
+	SHA256   string `json:"sha256"`
+}
 
-	// Fetch the latest hash value from DynamoDB
-	hash, _, err := GetLatestHashFilePair()
+// GetLatestHashFilePairAndZip returns the latest hash and filename pair from
+// DynamoDB and fetches the zip file from S3. RESPONSE_MODE selects how the
+// zip content is delivered:
+//
+//   - "base64" (the default): the whole object, base64-encoded into the
+//     response body, as before.
+//   - "presign": like base64 for objects under PRESIGN_THRESHOLD_BYTES, but
+//     for larger objects returns a 302 redirect to a short-lived pre-signed
+//     URL instead, since base64-encoding the whole object into the response
+//     body breaks past API Gateway/Lambda's 6MB payload limit.
+//
+// RESPONSE_MODE=stream is handled by the separate
+// GetLatestHashFilePairAndZipStream entrypoint, used by Lambda Function URLs
+// with response streaming enabled, since that path writes directly to an
+// http.ResponseWriter rather than returning a buffered Response.
+//
+// It returns the hash actually served, for the caller's audit log.
+func GetLatestHashFilePairAndZip(req *Request) (hash string, resp *Response, err error) {
+	bucketName, zipKey, hash, objectStore, err := resolveLatestZipObject()
 	if err != nil {
-		fmt.Printf("Error fetching latest hash from DynamoDB: %s\n", err.Error())
-		return &events.APIGatewayProxyResponse{
+		fmt.Println(err.Error())
+		return "", &Response{
 			StatusCode: http.StatusInternalServerError,
-			Body:       "Error fetching latest hash from DynamoDB: " + err.Error(),
+			Body:       err.Error(),
 		}, nil
 	}
+	filename := hash + ".zip"
+
+	if os.Getenv("RESPONSE_MODE") == "presign" {
+		size, _, err := objectStore.Head(bucketName, zipKey)
+		if err != nil {
+			fmt.Printf("Failed to head object %s: %s\n", zipKey, err.Error())
+			return hash, &Response{
+				StatusCode: http.StatusInternalServerError,
+				Body:       "Failed to head object: " + err.Error(),
+			}, nil
+		}
+		if size > presignThresholdBytes() {
+			resp, err := presignedZipResponse(objectStore, bucketName, zipKey, hash, filename, size)
+			return hash, resp, err
+		}
+	}
 
-	// Construct the path to the zip file in the S3 bucket
-	zipFilePath := hash + "/"
-	zipFileName, err := getZipFileFromFolder(bucketName, zipFilePath)
+	content, err := cachedGetObject(objectStore, bucketName, zipKey)
 	if err != nil {
-		fmt.Printf("Error fetching zip file from S3: %s\n", err.Error())
-		return &events.APIGatewayProxyResponse{
+		fmt.Printf("Failed to get object %s from S3: %s\n", zipKey, err.Error())
+		return hash, &Response{
 			StatusCode: http.StatusInternalServerError,
-			Body:       "Error fetching zip file from S3: " + err.Error(),
+			Body:       "Failed to get object from S3: " + err.Error(),
 		}, nil
 	}
 
-	// Get the zip file from S3
-	sess, err := session.NewSession()
+	// Encode the zip file content as base64
+	encodedZip := base64.StdEncoding.EncodeToString(content)
+
+	// Return the base64-encoded zip file content in response
+	return hash, &Response{
+		StatusCode: http.StatusOK,
+		Body:       encodedZip,
+		Headers: map[string]string{
+			"Content-Type":        "application/zip",
+			"Content-Disposition": fmt.Sprintf("attachment; filename=\"%s\"", filename),
+		},
+		IsBase64Encoded: true,
+	}, nil
+}
+
+// presignedZipResponse builds the 302 redirect + JSON metadata response for
+// RESPONSE_MODE=presign once an object has been found to exceed the
+// configured threshold.
+func presignedZipResponse(objectStore storage.ObjectStore, bucketName, zipKey, hash, filename string, size int64) (*Response, error) {
+	sha256Hex, err := sha256Object(objectStore, bucketName, zipKey, size)
 	if err != nil {
-		fmt.Printf("Failed to create AWS session: %s\n", err.Error())
-		return &events.APIGatewayProxyResponse{
+		fmt.Printf("Failed to hash object %s: %s\n", zipKey, err.Error())
+		return &Response{
 			StatusCode: http.StatusInternalServerError,
-			Body:       "Failed to create AWS session: " + err.Error(),
+			Body:       "Failed to hash object: " + err.Error(),
 		}, nil
 	}
 
-	svc := s3.New(sess)
-	input := &s3.GetObjectInput{
-		Bucket: aws.String(bucketName),
-		Key:    aws.String(zipFilePath + zipFileName),
-	}
-
-	result, err := svc.GetObject(input)
+	url, err := objectStore.PresignGetURL(bucketName, zipKey, presignTTL())
 	if err != nil {
-		fmt.Printf("Failed to get object %s from S3: %s\n", zipFilePath+zipFileName, err.Error())
-		return &events.APIGatewayProxyResponse{
+		fmt.Printf("Failed to presign object %s: %s\n", zipKey, err.Error())
+		return &Response{
 			StatusCode: http.StatusInternalServerError,
-			Body:       "Failed to get object from S3: " + err.Error(),
+			Body:       "Failed to presign object: " + err.Error(),
 		}, nil
 	}
-	defer result.Body.Close()
 
-	// Read the zip file content
-	buf := new(bytes.Buffer)
-	_, err = io.Copy(buf, result.Body)
+	body, err := json.Marshal(presignMetadata{Hash: hash, Filename: filename, Size: size, SHA256: sha256Hex})
 	if err != nil {
-		fmt.Printf("Failed to read object content: %s\n", err.Error())
-		return &events.APIGatewayProxyResponse{
+		return &Response{
 			StatusCode: http.StatusInternalServerError,
-			Body:       "Failed to read object content: " + err.Error(),
+			Body:       "Error encoding presign metadata: " + err.Error(),
 		}, nil
 	}
 
-	// Encode the zip file content as base64
-	encodedZip := base64.StdEncoding.EncodeToString(buf.Bytes())
-
-	// Set the filename using the hash value
-	filename := hash + ".zip"
-
-	// Return the base64-encoded zip file content in response
-	return &events.APIGatewayProxyResponse{
-		StatusCode: http.StatusOK,
-		Body:       encodedZip,
+	return &Response{
+		StatusCode: http.StatusFound,
+		Body:       string(body),
 		Headers: map[string]string{
-			"Content-Type":        "application/zip",
-			"Content-Disposition": fmt.Sprintf("attachment; filename=\"%s\"", filename),
+			"Content-Type": "application/json",
+			"Location":     url,
 		},
 	}, nil
 }
 
-// GetLatestHashFilePair gets the latest hash and filename pair from DynamoDB
-func GetLatestHashFilePair() (string, string, error) {
-	// Create a new AWS session with default configuration
-	sess, err := session.NewSession()
+// presignThresholdBytes returns the configurable size above which
+// RESPONSE_MODE=presign redirects instead of inlining the base64 body.
+func presignThresholdBytes() int64 {
+	if v := os.Getenv("PRESIGN_THRESHOLD_BYTES"); v != "" {
+		if n, err := strconv.ParseInt(v, 10, 64); err == nil && n > 0 {
+			return n
+		}
+	}
+	return defaultPresignThresholdBytes
+}
+
+// presignTTL returns the configurable lifetime of a presigned URL.
+func presignTTL() time.Duration {
+	if v := os.Getenv("PRESIGN_TTL_SECONDS"); v != "" {
+		if n, err := strconv.Atoi(v); err == nil && n > 0 {
+			return time.Duration(n) * time.Second
+		}
+	}
+	return defaultPresignTTL
+}
+
+// resolveLatestZipObject looks up the latest hash and the bucket/key of its
+// zip file, and returns an already-initialized object store alongside them.
+func resolveLatestZipObject() (bucketName, zipKey, hash string, objectStore storage.ObjectStore, err error) {
+	bucketName = os.Getenv("bucketName")
+	if bucketName == "" {
+		return "", "", "", nil, fmt.Errorf("S3 bucket name is not set")
+	}
+
+	hash, _, err = GetLatestHashFilePair()
+	if err != nil {
+		return "", "", "", nil, fmt.Errorf("error fetching latest hash from DynamoDB: %w", err)
+	}
+
+	zipFolder := hash + "/"
+	zipFileName, err := getZipFileFromFolder(bucketName, zipFolder)
 	if err != nil {
-		return "", "", fmt.Errorf("failed to create AWS session: %w", err)
+		return "", "", "", nil, fmt.Errorf("error fetching zip file from S3: %w", err)
 	}
 
-	// Create a DynamoDB service client
-	svc := dynamodb.New(sess)
+	objectStore, err = storage.NewObjectStore()
+	if err != nil {
+		return "", "", "", nil, fmt.Errorf("failed to initialize object store: %w", err)
+	}
 
-	// Prepare the input parameters for the Scan request
-	input := &dynamodb.ScanInput{
-		TableName: aws.String("file-script"),
+	return bucketName, zipFolder + zipFileName, hash, objectStore, nil
+}
+
+// GetLatestHashFilePair gets the latest hash and filename pair from the
+// configured metadata store, serving from latestHashCache when possible to
+// skip the Query on warm invocations.
+func GetLatestHashFilePair() (string, string, error) {
+	if cached, ok := latestHashCache.Get("latest"); ok {
+		return cached.Hash, cached.Filename, nil
 	}
 
-	// Scan the table
-	result, err := svc.Scan(input)
+	metadataStore, err := storage.NewMetadataStore()
 	if err != nil {
-		return "", "", fmt.Errorf("failed to scan DynamoDB table: %w", err)
+		return "", "", fmt.Errorf("failed to initialize metadata store: %w", err)
 	}
+	hash, filename, err := metadataStore.LatestRecord("file-script")
+	if err != nil {
+		return "", "", err
+	}
+
+	latestHashCache.Put("latest", latestHashValue{Hash: hash, Filename: filename}, latestHashTTL())
+	return hash, filename, nil
+}
+
+// defaultHistoryLimit is used by GetHashHistory when the caller doesn't
+// specify a limit, or specifies one that doesn't parse as a positive int.
+const defaultHistoryLimit = 20
 
-	if len(result.Items) == 0 {
-		return "", "", fmt.Errorf("no items found in DynamoDB table")
+// historyPage is the JSON shape returned by GetHashHistory.
+type historyPage struct {
+	Records    []storage.Record `json:"records"`
+	NextCursor string           `json:"nextCursor,omitempty"`
+}
+
+// GetHashHistory serves ?op=history&limit=&cursor=, returning a page of
+// past hash/filename/timestamp records, most recent first, with an opaque
+// cursor for the next page. A page covers many hashes rather than one, so
+// its first return value is always "" — there's no single hash to log.
+func GetHashHistory(req *Request) (hash string, resp *Response, err error) {
+	limit := defaultHistoryLimit
+	if v := req.QueryStringParameters["limit"]; v != "" {
+		if n, err := strconv.Atoi(v); err == nil && n > 0 {
+			limit = n
+		}
+	}
+
+	metadataStore, err := storage.NewMetadataStore()
+	if err != nil {
+		return "", &Response{
+			StatusCode: http.StatusInternalServerError,
+			Body:       "Failed to initialize metadata store: " + err.Error(),
+		}, nil
 	}
 
-	// Unmarshal the results into a slice of Item
-	var items []Item
-	err = dynamodbattribute.UnmarshalListOfMaps(result.Items, &items)
+	records, nextCursor, err := metadataStore.ListHashHistory("file-script", limit, req.QueryStringParameters["cursor"])
 	if err != nil {
-		return "", "", fmt.Errorf("failed to unmarshal DynamoDB scan result: %w", err)
+		fmt.Printf("Error listing hash history: %s\n", err.Error())
+		return "", &Response{
+			StatusCode: http.StatusInternalServerError,
+			Body:       "Error listing hash history: " + err.Error(),
+		}, nil
 	}
 
-	// Sort the items by timestamp in descending order
-	sort.Slice(items, func(i, j int) bool {
-		ti, _ := time.Parse(time.RFC3339, items[i].Timestamp)
-		tj, _ := time.Parse(time.RFC3339, items[j].Timestamp)
-		return ti.After(tj)
-	})
+	body, err := json.Marshal(historyPage{Records: records, NextCursor: nextCursor})
+	if err != nil {
+		return "", &Response{
+			StatusCode: http.StatusInternalServerError,
+			Body:       "Error encoding hash history: " + err.Error(),
+		}, nil
+	}
 
-	// Return the hash and filename of the latest item
-	latestItem := items[0]
-	return latestItem.Hash, latestItem.Filename, nil
+	return "", &Response{
+		StatusCode: http.StatusOK,
+		Body:       string(body),
+		Headers:    map[string]string{"Content-Type": "application/json"},
+	}, nil
 }
 
-// getZipFileFromFolder fetches the zip file from the specified S3 folder
-func getZipFileFromFolder(bucketName, folderPath string) (string, error) {
-	// Create a new AWS session with default configuration
-	sess, err := session.NewSession()
+// GetHashByID serves ?op=get&hash=, looking up a record directly instead of
+// by recency. It returns the requested hash only once a matching record was
+// actually found and returned to the caller.
+func GetHashByID(req *Request) (hash string, resp *Response, err error) {
+	requestedHash := req.QueryStringParameters["hash"]
+	if requestedHash == "" {
+		return "", &Response{
+			StatusCode: http.StatusBadRequest,
+			Body:       "Missing required query parameter: hash",
+		}, nil
+	}
+
+	metadataStore, err := storage.NewMetadataStore()
 	if err != nil {
-		return "", fmt.Errorf("failed to create AWS session: %w", err)
+		return "", &Response{
+			StatusCode: http.StatusInternalServerError,
+			Body:       "Failed to initialize metadata store: " + err.Error(),
+		}, nil
 	}
 
-	// Create an S3 service client
-	svc := s3.New(sess)
+	filename, timestamp, err := metadataStore.GetByHash("file-script", requestedHash)
+	if err != nil {
+		fmt.Printf("Error fetching hash %s: %s\n", requestedHash, err.Error())
+		return "", &Response{
+			StatusCode: http.StatusNotFound,
+			Body:       "Error fetching hash: " + err.Error(),
+		}, nil
+	}
 
-	// List objects in the folder
-	listInput := &s3.ListObjectsV2Input{
-		Bucket: aws.String(bucketName),
-		Prefix: aws.String(folderPath),
+	body, err := json.Marshal(storage.Record{Hash: requestedHash, Filename: filename, Timestamp: timestamp})
+	if err != nil {
+		return "", &Response{
+			StatusCode: http.StatusInternalServerError,
+			Body:       "Error encoding record: " + err.Error(),
+		}, nil
+	}
+
+	return requestedHash, &Response{
+		StatusCode: http.StatusOK,
+		Body:       string(body),
+		Headers:    map[string]string{"Content-Type": "application/json"},
+	}, nil
+}
+
+// getZipFileFromFolder fetches the zip file from the specified S3 folder,
+// serving from zipFolderCache when possible to skip the ListObjectsV2 call
+// on warm invocations.
+func getZipFileFromFolder(bucketName, folderPath string) (string, error) {
+	cacheKey := bucketName + "/" + folderPath
+	if zipFileName, ok := zipFolderCache.Get(cacheKey); ok {
+		return zipFileName, nil
+	}
+
+	objectStore, err := storage.NewObjectStore()
+	if err != nil {
+		return "", fmt.Errorf("failed to initialize object store: %w", err)
 	}
 
-	listOutput, err := svc.ListObjectsV2(listInput)
+	keys, err := objectStore.ListKeysWithPrefix(bucketName, folderPath)
 	if err != nil {
 		return "", fmt.Errorf("failed to list objects in S3 folder: %w", err)
 	}
 
 	// Find the zip file in the folder
-	for _, obj := range listOutput.Contents {
-		if strings.HasSuffix(*obj.Key, ".zip") {
-			return strings.TrimPrefix(*obj.Key, folderPath), nil
+	for _, key := range keys {
+		if strings.HasSuffix(key, ".zip") {
+			zipFileName := strings.TrimPrefix(key, folderPath)
+			zipFolderCache.Put(cacheKey, zipFileName, latestHashTTL())
+			return zipFileName, nil
 		}
 	}
 
@@ -0,0 +1,26 @@
+package functions
+
+import "strings"
+
+// Request is a provider-neutral representation of an incoming HTTP request.
+// Field names mirror events.APIGatewayProxyRequest so that adapting an AWS
+// event is a straight field copy; the Fn Project and Tencent SCF adapters in
+// pkg/handlers build one the same way from their own event types.
+type Request struct {
+	HTTPMethod            string
+	Path                  string
+	Headers               map[string]string
+	QueryStringParameters map[string]string
+	Body                  string
+}
+
+// HeaderValue looks up a header case-insensitively, since not every FaaS
+// provider normalizes header casing the way API Gateway does.
+func (r *Request) HeaderValue(name string) string {
+	for k, v := range r.Headers {
+		if strings.EqualFold(k, name) {
+			return v
+		}
+	}
+	return ""
+}
@@ -0,0 +1,12 @@
+package functions
+
+// Response is a provider-neutral HTTP response. Field names mirror
+// events.APIGatewayProxyResponse; pkg/handlers adapts one of these into
+// whichever FaaS provider's native response type the current entrypoint
+// needs.
+type Response struct {
+	StatusCode      int
+	Body            string
+	Headers         map[string]string
+	IsBase64Encoded bool
+}
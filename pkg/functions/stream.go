@@ -0,0 +1,80 @@
+package functions
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"net/http"
+
+	"go_lambdas/pkg/storage"
+)
+
+// streamChunkSize bounds how much of the zip object is held in memory at
+// once when streaming or hashing it, regardless of the object's total size.
+const streamChunkSize = 8 * 1024 * 1024 // 8MiB
+
+// GetLatestHashFilePairAndZipStream writes the latest hash's zip straight
+// through to w in fixed-size chunks, without base64-encoding or buffering
+// the whole object in memory. It is the RESPONSE_MODE=stream counterpart to
+// GetLatestHashFilePairAndZip, meant for Lambda Function URLs with response
+// streaming enabled (see lambdaurl.Start in bin/stream/main.go), where the
+// handler writes to a real http.ResponseWriter instead of returning a
+// buffered Response.
+//
+// It returns the served hash and a non-nil error on any failure, so callers
+// can tell a genuinely served request from one that only got an http.Error,
+// for accurate audit logging.
+func GetLatestHashFilePairAndZipStream(req *Request, w http.ResponseWriter) (hash string, err error) {
+	bucketName, zipKey, hash, objectStore, err := resolveLatestZipObject()
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return "", err
+	}
+
+	size, _, err := objectStore.Head(bucketName, zipKey)
+	if err != nil {
+		err = fmt.Errorf("failed to head object: %w", err)
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return hash, err
+	}
+
+	w.Header().Set("Content-Type", "application/zip")
+	w.Header().Set("Content-Disposition", fmt.Sprintf("attachment; filename=\"%s.zip\"", hash))
+	w.Header().Set("Content-Length", fmt.Sprintf("%d", size))
+	w.WriteHeader(http.StatusOK)
+
+	if err := streamObjectRange(objectStore, bucketName, zipKey, size, w); err != nil {
+		return hash, err
+	}
+	return hash, nil
+}
+
+// streamObjectRange copies [0, size) of the object to w in streamChunkSize
+// pieces, so peak memory use doesn't scale with the object's size.
+func streamObjectRange(objectStore storage.ObjectStore, bucketName, key string, size int64, w io.Writer) error {
+	for offset := int64(0); offset < size; offset += streamChunkSize {
+		end := offset + streamChunkSize - 1
+		if end >= size {
+			end = size - 1
+		}
+		chunk, err := objectStore.GetRange(bucketName, key, offset, end)
+		if err != nil {
+			return fmt.Errorf("failed to fetch chunk [%d-%d]: %w", offset, end, err)
+		}
+		if _, err := w.Write(chunk); err != nil {
+			return fmt.Errorf("failed to write chunk to response: %w", err)
+		}
+	}
+	return nil
+}
+
+// sha256Object computes the SHA-256 of an object by streaming it through in
+// fixed-size chunks rather than buffering the whole thing.
+func sha256Object(objectStore storage.ObjectStore, bucketName, key string, size int64) (string, error) {
+	h := sha256.New()
+	if err := streamObjectRange(objectStore, bucketName, key, size, h); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(h.Sum(nil)), nil
+}
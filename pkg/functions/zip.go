@@ -0,0 +1,645 @@
+package functions
+
+import (
+	"bytes"
+	"compress/flate"
+	"encoding/base64"
+	"encoding/binary"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"go_lambdas/pkg/storage"
+)
+
+const (
+	zipEOCDSignature       = 0x06054b50
+	zipEOCDLocatorSig      = 0x07064b50
+	zipEOCD64Signature     = 0x06064b50
+	zipEOCDMinSize         = 22
+	zipEOCDLocatorSize     = 20
+	zipLocalHeaderMinSize  = 30
+	zipTailFetchSize       = 64 * 1024
+	zip64ExtraFieldTag     = 0x0001
+	defaultMaxEntrySizeCap = 512 * 1024 * 1024 // 512MiB
+)
+
+// ZipEntry describes a single file inside a stored zip archive, as recovered
+// from its central directory record.
+type ZipEntry struct {
+	Name              string    `json:"name"`
+	Method            uint16    `json:"method"`
+	CRC32             uint32    `json:"crc32"`
+	CompressedSize    uint64    `json:"compressedSize"`
+	UncompressedSize  uint64    `json:"uncompressedSize"`
+	LocalHeaderOffset uint64    `json:"-"`
+	ModTime           time.Time `json:"modTime"`
+	Encrypted         bool      `json:"-"`
+}
+
+// zipIndex is the parsed central directory of a zip object, cached per
+// hash+ETag so warm invocations don't re-fetch the EOCD.
+type zipIndex struct {
+	entries []ZipEntry
+	byName  map[string]int
+}
+
+// zipIndexCache is a tiny process-level LRU keyed by "hash:etag". It exists
+// purely to skip the EOCD round-trip to S3 on warm Lambda containers.
+type zipIndexCache struct {
+	mu       sync.Mutex
+	capacity int
+	order    []string
+	entries  map[string]*zipIndex
+}
+
+func newZipIndexCache(capacity int) *zipIndexCache {
+	return &zipIndexCache{
+		capacity: capacity,
+		entries:  make(map[string]*zipIndex),
+	}
+}
+
+func (c *zipIndexCache) get(key string) (*zipIndex, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	idx, ok := c.entries[key]
+	if ok {
+		c.touch(key)
+	}
+	return idx, ok
+}
+
+func (c *zipIndexCache) put(key string, idx *zipIndex) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if _, exists := c.entries[key]; !exists {
+		if len(c.order) >= c.capacity {
+			oldest := c.order[0]
+			c.order = c.order[1:]
+			delete(c.entries, oldest)
+		}
+		c.order = append(c.order, key)
+	} else {
+		c.touch(key)
+	}
+	c.entries[key] = idx
+}
+
+// touch must be called with c.mu held.
+func (c *zipIndexCache) touch(key string) {
+	for i, k := range c.order {
+		if k == key {
+			c.order = append(c.order[:i], c.order[i+1:]...)
+			c.order = append(c.order, key)
+			return
+		}
+	}
+}
+
+var globalZipIndexCache = newZipIndexCache(16)
+
+// maxEntrySizeBytes returns the configurable cap on a single entry's
+// (compressed or uncompressed) size, above which GetZipEntry refuses to
+// serve the entry rather than risk unbounded memory/time use.
+func maxEntrySizeBytes() uint64 {
+	if v := os.Getenv("MAX_ZIP_ENTRY_SIZE_BYTES"); v != "" {
+		if n, err := strconv.ParseUint(v, 10, 64); err == nil && n > 0 {
+			return n
+		}
+	}
+	return defaultMaxEntrySizeCap
+}
+
+// GetZipListing handles GET /?list=1&prefix=... by returning a JSON listing
+// of the entries inside the latest hash's zip, optionally filtered by
+// prefix, without downloading the archive. It returns the hash of the zip
+// actually listed, for the caller's audit log.
+func GetZipListing(req *Request) (hash string, resp *Response, err error) {
+	bucketName, zipKey, hash, store, err := resolveLatestZip()
+	if err != nil {
+		fmt.Printf("Error resolving latest zip: %s\n", err.Error())
+		return "", &Response{
+			StatusCode: http.StatusInternalServerError,
+			Body:       "Error resolving latest zip: " + err.Error(),
+		}, nil
+	}
+
+	idx, err := loadZipIndex(store, bucketName, zipKey, hash)
+	if err != nil {
+		fmt.Printf("Error reading zip index: %s\n", err.Error())
+		return hash, &Response{
+			StatusCode: http.StatusInternalServerError,
+			Body:       "Error reading zip index: " + err.Error(),
+		}, nil
+	}
+
+	prefix := req.QueryStringParameters["prefix"]
+	listed := make([]ZipEntry, 0, len(idx.entries))
+	for _, entry := range idx.entries {
+		if prefix != "" && !strings.HasPrefix(entry.Name, prefix) {
+			continue
+		}
+		listed = append(listed, entry)
+	}
+
+	body, err := json.Marshal(listed)
+	if err != nil {
+		return hash, &Response{
+			StatusCode: http.StatusInternalServerError,
+			Body:       "Error encoding zip listing: " + err.Error(),
+		}, nil
+	}
+
+	return hash, &Response{
+		StatusCode: http.StatusOK,
+		Body:       string(body),
+		Headers: map[string]string{
+			"Content-Type": "application/json",
+		},
+	}, nil
+}
+
+// GetZipEntry handles GET /?entry=path/inside.zip by streaming a single
+// entry out of the latest hash's zip, honoring the HTTP Range header on the
+// incoming request so large entries can be fetched in pieces. It returns the
+// hash of the zip the entry was served from, for the caller's audit log.
+func GetZipEntry(req *Request) (hash string, resp *Response, err error) {
+	entryName := req.QueryStringParameters["entry"]
+	if entryName == "" {
+		return "", &Response{
+			StatusCode: http.StatusBadRequest,
+			Body:       "Missing entry query parameter",
+		}, nil
+	}
+
+	bucketName, zipKey, hash, store, err := resolveLatestZip()
+	if err != nil {
+		fmt.Printf("Error resolving latest zip: %s\n", err.Error())
+		return "", &Response{
+			StatusCode: http.StatusInternalServerError,
+			Body:       "Error resolving latest zip: " + err.Error(),
+		}, nil
+	}
+
+	idx, err := loadZipIndex(store, bucketName, zipKey, hash)
+	if err != nil {
+		fmt.Printf("Error reading zip index: %s\n", err.Error())
+		return hash, &Response{
+			StatusCode: http.StatusInternalServerError,
+			Body:       "Error reading zip index: " + err.Error(),
+		}, nil
+	}
+
+	pos, ok := idx.byName[entryName]
+	if !ok {
+		return hash, &Response{
+			StatusCode: http.StatusNotFound,
+			Body:       fmt.Sprintf("Entry %q not found in zip", entryName),
+		}, nil
+	}
+	entry := idx.entries[pos]
+
+	if entry.Encrypted {
+		return hash, &Response{
+			StatusCode: http.StatusForbidden,
+			Body:       fmt.Sprintf("Entry %q is encrypted and cannot be served", entryName),
+		}, nil
+	}
+	if cap := maxEntrySizeBytes(); entry.CompressedSize > cap || entry.UncompressedSize > cap {
+		return hash, &Response{
+			StatusCode: http.StatusRequestEntityTooLarge,
+			Body:       fmt.Sprintf("Entry %q exceeds the configured size cap of %d bytes", entryName, cap),
+		}, nil
+	}
+
+	if entry.Method != 0 && entry.Method != 8 {
+		return hash, &Response{
+			StatusCode: http.StatusInternalServerError,
+			Body:       fmt.Sprintf("unsupported compression method %d for entry %q", entry.Method, entry.Name),
+		}, nil
+	}
+
+	status := http.StatusOK
+	start, end := 0, int(entry.UncompressedSize)-1
+	headers := map[string]string{
+		"Content-Type":  contentTypeForEntry(entry.Name),
+		"Accept-Ranges": "bytes",
+	}
+
+	if rangeHeader := req.HeaderValue("Range"); rangeHeader != "" {
+		var rangeErr error
+		start, end, rangeErr = parseByteRange(rangeHeader, int(entry.UncompressedSize))
+		if rangeErr != nil {
+			return hash, &Response{
+				StatusCode: http.StatusRequestedRangeNotSatisfiable,
+				Body:       rangeErr.Error(),
+				Headers: map[string]string{
+					"Content-Range": fmt.Sprintf("bytes */%d", entry.UncompressedSize),
+				},
+			}, nil
+		}
+		status = http.StatusPartialContent
+		headers["Content-Range"] = fmt.Sprintf("bytes %d-%d/%d", start, end, entry.UncompressedSize)
+	}
+
+	content, err := fetchZipEntryRange(store, bucketName, zipKey, entry, start, end)
+	if err != nil {
+		fmt.Printf("Error fetching zip entry %q: %s\n", entryName, err.Error())
+		return hash, &Response{
+			StatusCode: http.StatusInternalServerError,
+			Body:       "Error fetching zip entry: " + err.Error(),
+		}, nil
+	}
+
+	headers["Content-Length"] = strconv.Itoa(len(content))
+	return hash, &Response{
+		StatusCode:      status,
+		Body:            base64.StdEncoding.EncodeToString(content),
+		IsBase64Encoded: true,
+		Headers:         headers,
+	}, nil
+}
+
+// resolveLatestZip looks up the latest hash/zip key pair and returns an
+// already-initialized object store alongside it, so listing and entry
+// handlers don't each need to repeat the metadata/object store setup.
+func resolveLatestZip() (bucketName, zipKey, hash string, store storage.ObjectStore, err error) {
+	bucketName = os.Getenv("bucketName")
+	if bucketName == "" {
+		return "", "", "", nil, fmt.Errorf("S3 bucket name is not set")
+	}
+
+	hash, _, err = GetLatestHashFilePair()
+	if err != nil {
+		return "", "", "", nil, fmt.Errorf("failed to fetch latest hash: %w", err)
+	}
+
+	zipFolder := hash + "/"
+	zipFileName, err := getZipFileFromFolder(bucketName, zipFolder)
+	if err != nil {
+		return "", "", "", nil, fmt.Errorf("failed to find zip file: %w", err)
+	}
+
+	store, err = storage.NewObjectStore()
+	if err != nil {
+		return "", "", "", nil, fmt.Errorf("failed to initialize object store: %w", err)
+	}
+
+	return bucketName, zipFolder + zipFileName, hash, store, nil
+}
+
+// loadZipIndex returns the parsed central directory for the given zip
+// object, consulting the process-level cache (keyed by hash + ETag) before
+// falling back to fetching and parsing the end-of-central-directory record.
+func loadZipIndex(store storage.ObjectStore, bucketName, zipKey, hash string) (*zipIndex, error) {
+	size, etag, err := store.Head(bucketName, zipKey)
+	if err != nil {
+		return nil, fmt.Errorf("failed to head object %s: %w", zipKey, err)
+	}
+
+	cacheKey := hash + ":" + etag
+	if idx, ok := globalZipIndexCache.get(cacheKey); ok {
+		return idx, nil
+	}
+
+	entries, err := fetchCentralDirectory(store, bucketName, zipKey, size)
+	if err != nil {
+		return nil, err
+	}
+
+	idx := &zipIndex{entries: entries, byName: make(map[string]int, len(entries))}
+	for i, entry := range entries {
+		idx.byName[entry.Name] = i
+	}
+	globalZipIndexCache.put(cacheKey, idx)
+	return idx, nil
+}
+
+// fetchCentralDirectory fetches just enough of the tail of the zip object to
+// locate and parse its end-of-central-directory record (expanding the
+// fetched range if a Zip64 EOCD locator is present), then fetches and parses
+// the central directory it points to.
+func fetchCentralDirectory(store storage.ObjectStore, bucketName, zipKey string, size int64) ([]ZipEntry, error) {
+	tailSize := int64(zipTailFetchSize)
+	if tailSize > size {
+		tailSize = size
+	}
+	tailStart := size - tailSize
+
+	tail, err := store.GetRange(bucketName, zipKey, tailStart, size-1)
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch zip tail: %w", err)
+	}
+
+	eocdPos := bytes.LastIndex(tail, le32Bytes(zipEOCDSignature))
+	if eocdPos < 0 {
+		// The comment trailing the EOCD may push it further back than our
+		// fixed tail window; give up rather than scanning the whole object.
+		return nil, fmt.Errorf("end of central directory record not found in zip tail")
+	}
+	eocd := tail[eocdPos:]
+	if len(eocd) < zipEOCDMinSize {
+		return nil, fmt.Errorf("truncated end of central directory record")
+	}
+
+	cdSize := uint64(binary.LittleEndian.Uint32(eocd[12:16]))
+	cdOffset := uint64(binary.LittleEndian.Uint32(eocd[16:20]))
+
+	// A Zip64 archive leaves 0xFFFFFFFF sentinels in the classic EOCD and
+	// stores the real values in a Zip64 EOCD record, found via a locator
+	// immediately preceding the classic EOCD.
+	if cdSize == 0xFFFFFFFF || cdOffset == 0xFFFFFFFF {
+		locatorEnd := tailStart + int64(eocdPos)
+		locatorStart := locatorEnd - zipEOCDLocatorSize
+		if locatorStart < 0 {
+			return nil, fmt.Errorf("zip64 end of central directory locator out of range")
+		}
+		locator, err := store.GetRange(bucketName, zipKey, locatorStart, locatorEnd-1)
+		if err != nil {
+			return nil, fmt.Errorf("failed to fetch zip64 EOCD locator: %w", err)
+		}
+		if len(locator) < zipEOCDLocatorSize || binary.LittleEndian.Uint32(locator[0:4]) != zipEOCDLocatorSig {
+			return nil, fmt.Errorf("zip64 end of central directory locator not found")
+		}
+		zip64EOCDOffset := int64(binary.LittleEndian.Uint64(locator[8:16]))
+
+		zip64Header, err := store.GetRange(bucketName, zipKey, zip64EOCDOffset, zip64EOCDOffset+55)
+		if err != nil {
+			return nil, fmt.Errorf("failed to fetch zip64 EOCD record: %w", err)
+		}
+		if len(zip64Header) < 56 || binary.LittleEndian.Uint32(zip64Header[0:4]) != zipEOCD64Signature {
+			return nil, fmt.Errorf("zip64 end of central directory record not found")
+		}
+		cdSize = binary.LittleEndian.Uint64(zip64Header[40:48])
+		cdOffset = binary.LittleEndian.Uint64(zip64Header[48:56])
+	}
+
+	if cdSize > uint64(defaultMaxEntrySizeCap) {
+		return nil, fmt.Errorf("central directory size %d exceeds parse cap", cdSize)
+	}
+
+	cd, err := store.GetRange(bucketName, zipKey, int64(cdOffset), int64(cdOffset+cdSize)-1)
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch central directory: %w", err)
+	}
+
+	return parseCentralDirectory(cd)
+}
+
+// parseCentralDirectory walks a buffer of consecutive central directory file
+// headers and returns one ZipEntry per header.
+func parseCentralDirectory(cd []byte) ([]ZipEntry, error) {
+	const cdHeaderSig = 0x02014b50
+	const cdHeaderMinSize = 46
+
+	var entries []ZipEntry
+	off := 0
+	for off+cdHeaderMinSize <= len(cd) {
+		if binary.LittleEndian.Uint32(cd[off:off+4]) != cdHeaderSig {
+			break
+		}
+
+		generalPurposeFlag := binary.LittleEndian.Uint16(cd[off+8 : off+10])
+		method := binary.LittleEndian.Uint16(cd[off+10 : off+12])
+		modTimeRaw := binary.LittleEndian.Uint16(cd[off+12 : off+14])
+		modDateRaw := binary.LittleEndian.Uint16(cd[off+14 : off+16])
+		crc32 := binary.LittleEndian.Uint32(cd[off+16 : off+20])
+		compressedSize := uint64(binary.LittleEndian.Uint32(cd[off+20 : off+24]))
+		uncompressedSize := uint64(binary.LittleEndian.Uint32(cd[off+24 : off+28]))
+		nameLen := int(binary.LittleEndian.Uint16(cd[off+28 : off+30]))
+		extraLen := int(binary.LittleEndian.Uint16(cd[off+30 : off+32]))
+		commentLen := int(binary.LittleEndian.Uint16(cd[off+32 : off+34]))
+		localHeaderOffset := uint64(binary.LittleEndian.Uint32(cd[off+42 : off+46]))
+
+		nameStart := off + cdHeaderMinSize
+		nameEnd := nameStart + nameLen
+		extraEnd := nameEnd + extraLen
+		if extraEnd > len(cd) {
+			return nil, fmt.Errorf("truncated central directory entry")
+		}
+		name := string(cd[nameStart:nameEnd])
+
+		compressedSize, uncompressedSize, localHeaderOffset = applyZip64Extra(
+			cd[nameEnd:extraEnd], compressedSize, uncompressedSize, localHeaderOffset)
+
+		entries = append(entries, ZipEntry{
+			Name:              name,
+			Method:            method,
+			CRC32:             crc32,
+			CompressedSize:    compressedSize,
+			UncompressedSize:  uncompressedSize,
+			LocalHeaderOffset: localHeaderOffset,
+			ModTime:           dosTimeToTime(modDateRaw, modTimeRaw),
+			Encrypted:         generalPurposeFlag&0x1 != 0,
+		})
+
+		off = extraEnd + commentLen
+	}
+	return entries, nil
+}
+
+// applyZip64Extra overrides the classic 32-bit size/offset fields with their
+// 64-bit counterparts from the Zip64 extended information extra field, when
+// present, in the order mandated by the spec (uncompressed, compressed,
+// local header offset — only for fields that were 0xFFFFFFFF).
+func applyZip64Extra(extra []byte, compressedSize, uncompressedSize, localHeaderOffset uint64) (uint64, uint64, uint64) {
+	for len(extra) >= 4 {
+		tag := binary.LittleEndian.Uint16(extra[0:2])
+		size := int(binary.LittleEndian.Uint16(extra[2:4]))
+		if len(extra) < 4+size {
+			break
+		}
+		if tag == zip64ExtraFieldTag {
+			data := extra[4 : 4+size]
+			idx := 0
+			if uncompressedSize == 0xFFFFFFFF && idx+8 <= len(data) {
+				uncompressedSize = binary.LittleEndian.Uint64(data[idx : idx+8])
+				idx += 8
+			}
+			if compressedSize == 0xFFFFFFFF && idx+8 <= len(data) {
+				compressedSize = binary.LittleEndian.Uint64(data[idx : idx+8])
+				idx += 8
+			}
+			if localHeaderOffset == 0xFFFFFFFF && idx+8 <= len(data) {
+				localHeaderOffset = binary.LittleEndian.Uint64(data[idx : idx+8])
+				idx += 8
+			}
+		}
+		extra = extra[4+size:]
+	}
+	return compressedSize, uncompressedSize, localHeaderOffset
+}
+
+// localDataOffset fetches just the entry's local file header (not its data)
+// to find where the entry's actual data begins: the local header's
+// name/extra field lengths can differ from the central directory's, so this
+// can't be computed from the central directory alone.
+func localDataOffset(store storage.ObjectStore, bucketName, zipKey string, entry ZipEntry) (uint64, error) {
+	header, err := store.GetRange(bucketName, zipKey, int64(entry.LocalHeaderOffset), int64(entry.LocalHeaderOffset)+zipLocalHeaderMinSize-1)
+	if err != nil {
+		return 0, fmt.Errorf("failed to fetch local file header: %w", err)
+	}
+	if len(header) < zipLocalHeaderMinSize {
+		return 0, fmt.Errorf("truncated local file header")
+	}
+	const localHeaderSig = 0x04034b50
+	if binary.LittleEndian.Uint32(header[0:4]) != localHeaderSig {
+		return 0, fmt.Errorf("local file header signature mismatch")
+	}
+	nameLen := int(binary.LittleEndian.Uint16(header[26:28]))
+	extraLen := int(binary.LittleEndian.Uint16(header[28:30]))
+	return entry.LocalHeaderOffset + uint64(zipLocalHeaderMinSize+nameLen+extraLen), nil
+}
+
+// fetchZipEntryRange returns the [start, end] (inclusive, relative to the
+// entry's uncompressed data) byte range of entry's content, without ever
+// buffering the whole decompressed entry.
+//
+// For a stored (uncompressed) entry, start/end map directly onto the
+// object's byte offsets, so this issues one precisely-sized ranged GET. For
+// a deflate entry there's no random access into the compressed stream, so
+// this fetches the whole compressed entry and inflates from the beginning, but discards
+// bytes before start as it goes and stops as soon as end has been read,
+// rather than buffering the whole decompressed entry.
+func fetchZipEntryRange(store storage.ObjectStore, bucketName, zipKey string, entry ZipEntry, start, end int) ([]byte, error) {
+	dataOffset, err := localDataOffset(store, bucketName, zipKey, entry)
+	if err != nil {
+		return nil, err
+	}
+	length := end - start + 1
+
+	switch entry.Method {
+	case 0: // stored
+		return store.GetRange(bucketName, zipKey, int64(dataOffset)+int64(start), int64(dataOffset)+int64(end))
+	case 8: // deflate
+		compressed, err := store.GetRange(bucketName, zipKey, int64(dataOffset), int64(dataOffset+entry.CompressedSize)-1)
+		if err != nil {
+			return nil, fmt.Errorf("failed to fetch compressed entry data: %w", err)
+		}
+		r := flate.NewReader(bytes.NewReader(compressed))
+		defer r.Close()
+		if start > 0 {
+			if _, err := io.CopyN(io.Discard, r, int64(start)); err != nil {
+				return nil, fmt.Errorf("failed to seek to range start: %w", err)
+			}
+		}
+		content := make([]byte, length)
+		if _, err := io.ReadFull(r, content); err != nil {
+			return nil, fmt.Errorf("failed to inflate entry range: %w", err)
+		}
+		return content, nil
+	default:
+		return nil, fmt.Errorf("unsupported compression method %d for entry %q", entry.Method, entry.Name)
+	}
+}
+
+// parseByteRange parses a single-range "bytes=start-end" HTTP Range header
+// value against a resource of the given total length.
+func parseByteRange(header string, total int) (start, end int, err error) {
+	const prefix = "bytes="
+	if !strings.HasPrefix(header, prefix) {
+		return 0, 0, fmt.Errorf("unsupported range unit")
+	}
+	spec := strings.TrimPrefix(header, prefix)
+	if strings.Contains(spec, ",") {
+		return 0, 0, fmt.Errorf("multi-range requests are not supported")
+	}
+
+	parts := strings.SplitN(spec, "-", 2)
+	if len(parts) != 2 {
+		return 0, 0, fmt.Errorf("malformed range header")
+	}
+
+	if parts[0] == "" {
+		// Suffix range: last N bytes.
+		n, convErr := strconv.Atoi(parts[1])
+		if convErr != nil || n <= 0 {
+			return 0, 0, fmt.Errorf("malformed suffix range")
+		}
+		if n > total {
+			n = total
+		}
+		return total - n, total - 1, nil
+	}
+
+	start, convErr := strconv.Atoi(parts[0])
+	if convErr != nil || start < 0 || start >= total {
+		return 0, 0, fmt.Errorf("range start out of bounds")
+	}
+	if parts[1] == "" {
+		return start, total - 1, nil
+	}
+	end, convErr = strconv.Atoi(parts[1])
+	if convErr != nil || end < start {
+		return 0, 0, fmt.Errorf("malformed range end")
+	}
+	if end >= total {
+		end = total - 1
+	}
+	return start, end, nil
+}
+
+// contentTypeForEntry guesses a Content-Type from an entry's file
+// extension, falling back to a generic binary stream.
+func contentTypeForEntry(name string) string {
+	if ct := mimeTypeByExtension(strings.ToLower(name)); ct != "" {
+		return ct
+	}
+	return "application/octet-stream"
+}
+
+func mimeTypeByExtension(name string) string {
+	switch {
+	case strings.HasSuffix(name, ".html"), strings.HasSuffix(name, ".htm"):
+		return "text/html"
+	case strings.HasSuffix(name, ".css"):
+		return "text/css"
+	case strings.HasSuffix(name, ".js"):
+		return "application/javascript"
+	case strings.HasSuffix(name, ".json"):
+		return "application/json"
+	case strings.HasSuffix(name, ".png"):
+		return "image/png"
+	case strings.HasSuffix(name, ".jpg"), strings.HasSuffix(name, ".jpeg"):
+		return "image/jpeg"
+	case strings.HasSuffix(name, ".svg"):
+		return "image/svg+xml"
+	case strings.HasSuffix(name, ".txt"):
+		return "text/plain"
+	default:
+		return ""
+	}
+}
+
+// le32Bytes returns the little-endian byte encoding of a 32-bit signature,
+// for use with bytes.LastIndex.
+func le32Bytes(sig uint32) []byte {
+	b := make([]byte, 4)
+	binary.LittleEndian.PutUint32(b, sig)
+	return b
+}
+
+// dosTimeToTime converts MS-DOS date/time fields (as stored in zip headers)
+// to a time.Time in UTC.
+func dosTimeToTime(dosDate, dosTime uint16) time.Time {
+	year := int(dosDate>>9) + 1980
+	month := int((dosDate >> 5) & 0xF)
+	day := int(dosDate & 0x1F)
+	hour := int(dosTime >> 11)
+	minute := int((dosTime >> 5) & 0x3F)
+	second := int(dosTime&0x1F) * 2
+	if month == 0 {
+		month = 1
+	}
+	if day == 0 {
+		day = 1
+	}
+	return time.Date(year, time.Month(month), day, hour, minute, second, 0, time.UTC)
+}
@@ -0,0 +1,73 @@
+package handler
+
+import (
+	"io"
+	"net/http"
+
+	"go_lambdas/pkg/functions"
+
+	"github.com/aws/aws-lambda-go/events"
+	scfevents "github.com/tencentyun/scf-go-lib/events"
+)
+
+// requestFromAPIGateway adapts an AWS API Gateway proxy request into the
+// provider-neutral functions.Request that pkg/functions operates on.
+func requestFromAPIGateway(req *events.APIGatewayProxyRequest) *functions.Request {
+	return &functions.Request{
+		HTTPMethod:            req.HTTPMethod,
+		Path:                  req.Path,
+		Headers:               req.Headers,
+		QueryStringParameters: req.QueryStringParameters,
+		Body:                  req.Body,
+	}
+}
+
+// requestFromFn adapts a Fn Project net/http.Request (the shape fdk.HTTPHandler
+// hands us) into a functions.Request.
+func requestFromFn(r *http.Request) (*functions.Request, error) {
+	body, err := io.ReadAll(r.Body)
+	if err != nil {
+		return nil, err
+	}
+
+	headers := make(map[string]string, len(r.Header))
+	for k, values := range r.Header {
+		if len(values) > 0 {
+			headers[k] = values[0]
+		}
+	}
+
+	query := make(map[string]string, len(r.URL.Query()))
+	for k, values := range r.URL.Query() {
+		if len(values) > 0 {
+			query[k] = values[0]
+		}
+	}
+
+	return &functions.Request{
+		HTTPMethod:            r.Method,
+		Path:                  r.URL.Path,
+		Headers:               headers,
+		QueryStringParameters: query,
+		Body:                  string(body),
+	}, nil
+}
+
+// requestFromSCF adapts a Tencent SCF API gateway event into a
+// functions.Request.
+func requestFromSCF(req scfevents.APIGatewayRequest) *functions.Request {
+	query := make(map[string]string, len(req.QueryString))
+	for k, values := range req.QueryString {
+		if len(values) > 0 {
+			query[k] = values[0]
+		}
+	}
+
+	return &functions.Request{
+		HTTPMethod:            req.Method,
+		Path:                  req.Path,
+		Headers:               req.Headers,
+		QueryStringParameters: query,
+		Body:                  req.Body,
+	}
+}
@@ -1,19 +1,74 @@
 package handler
 
 import (
+	"encoding/base64"
+	"net/http"
+
+	"go_lambdas/pkg/functions"
+
 	"github.com/aws/aws-lambda-go/events"
+	scfevents "github.com/tencentyun/scf-go-lib/events"
 )
 
-// APIResponse represents the response structure for the APIGatewayProxyResponse
+// APIResponse represents a provider-neutral HTTP response, converted to
+// whichever FaaS provider's native response type the current entrypoint
+// needs via the To*Response receivers below.
 type APIResponse struct {
-	StatusCode int
-	Body       string
+	StatusCode      int
+	Body            string
+	Headers         map[string]string
+	IsBase64Encoded bool
+}
+
+// FromFunctionsResponse adapts a functions.Response (already provider-
+// neutral) into an APIResponse, so it can be converted onward.
+func FromFunctionsResponse(r *functions.Response) *APIResponse {
+	return &APIResponse{
+		StatusCode:      r.StatusCode,
+		Body:            r.Body,
+		Headers:         r.Headers,
+		IsBase64Encoded: r.IsBase64Encoded,
+	}
 }
 
 // receiver function: converting APIResponse objects to events.APIGatewayProxyResponse
 func (resp *APIResponse) ToAPIGatewayProxyResponse() *events.APIGatewayProxyResponse {
 	return &events.APIGatewayProxyResponse{
-		StatusCode: resp.StatusCode,
-		Body:       resp.Body,
+		StatusCode:      resp.StatusCode,
+		Body:            resp.Body,
+		Headers:         resp.Headers,
+		IsBase64Encoded: resp.IsBase64Encoded,
+	}
+}
+
+// ToFnResponse writes the response to a Fn Project http.ResponseWriter (the
+// shape fdk.HTTPHandler expects from a standard net/http.Handler). Unlike
+// API Gateway/SCF, Fn Project has no IsBase64Encoded convention of its own,
+// so a base64-encoded Body must be decoded here before it's written.
+func (resp *APIResponse) ToFnResponse(w http.ResponseWriter) {
+	body := []byte(resp.Body)
+	if resp.IsBase64Encoded {
+		decoded, err := base64.StdEncoding.DecodeString(resp.Body)
+		if err != nil {
+			http.Error(w, "Error decoding response body: "+err.Error(), http.StatusInternalServerError)
+			return
+		}
+		body = decoded
+	}
+
+	for k, v := range resp.Headers {
+		w.Header().Set(k, v)
+	}
+	w.WriteHeader(resp.StatusCode)
+	w.Write(body)
+}
+
+// ToSCFResponse converts the response to a Tencent SCF API gateway response.
+func (resp *APIResponse) ToSCFResponse() *scfevents.APIGatewayResponse {
+	return &scfevents.APIGatewayResponse{
+		StatusCode:      resp.StatusCode,
+		Body:            resp.Body,
+		Headers:         resp.Headers,
+		IsBase64Encoded: resp.IsBase64Encoded,
 	}
 }
@@ -1,20 +1,165 @@
 package handler
 
 import (
-	"go_lambdas/pkg/functions"
+	"context"
+	"crypto/subtle"
+	"encoding/json"
+	"fmt"
 	"net/http"
+	"os"
+	"time"
+
+	"go_lambdas/pkg/accesskey"
+	"go_lambdas/pkg/functions"
 
 	"github.com/aws/aws-lambda-go/events"
+	scfevents "github.com/tencentyun/scf-go-lib/events"
 )
 
+// Handler is the AWS Lambda entrypoint, wired up in bin/main.go via
+// lambda.Start.
 func Handler(req *events.APIGatewayProxyRequest) (*events.APIGatewayProxyResponse, error) {
+	return route(requestFromAPIGateway(req)).ToAPIGatewayProxyResponse(), nil
+}
+
+// FnHandler is the Fn Project entrypoint. It is wrapped with fdk.HTTPHandler
+// in cmd/fn/main.go, since fdk hands us a standard net/http.Handler.
+func FnHandler(w http.ResponseWriter, r *http.Request) {
+	req, err := requestFromFn(r)
+	if err != nil {
+		http.Error(w, "Invalid request: "+err.Error(), http.StatusBadRequest)
+		return
+	}
+	route(req).ToFnResponse(w)
+}
+
+// SCFHandler is the Tencent SCF entrypoint, started via
+// cloudfunction.Start in cmd/scf/main.go.
+func SCFHandler(ctx context.Context, req scfevents.APIGatewayRequest) (*scfevents.APIGatewayResponse, error) {
+	return route(requestFromSCF(req)).ToSCFResponse(), nil
+}
+
+// route dispatches a provider-neutral request to the GET/POST handlers
+// shared by every FaaS entrypoint.
+func route(req *functions.Request) *APIResponse {
 	switch req.HTTPMethod {
 	case "GET":
-		return functions.GetLatestHashFilePairAndZip(req)
+		return handleGet(req)
+	case "POST":
+		return handlePost(req)
 	default:
-		return &events.APIGatewayProxyResponse{
+		return &APIResponse{
 			StatusCode: http.StatusMethodNotAllowed,
 			Body:       "Method not allowed",
-		}, nil
+		}
+	}
+}
+
+// handleGet authenticates the request against the access-key subsystem
+// before serving any of the zip-download routes, and logs the key ID
+// alongside the served hash for auditing.
+func handleGet(req *functions.Request) *APIResponse {
+	keyID, err := accesskey.Verify(req)
+	if err != nil {
+		fmt.Printf("Access key verification failed: %s\n", err.Error())
+		return &APIResponse{
+			StatusCode: http.StatusUnauthorized,
+			Body:       "Unauthorized: " + err.Error(),
+		}
+	}
+
+	qs := req.QueryStringParameters
+	var hash string
+	var resp *functions.Response
+	switch {
+	case qs["list"] != "":
+		hash, resp, err = functions.GetZipListing(req)
+	case qs["entry"] != "":
+		hash, resp, err = functions.GetZipEntry(req)
+	case qs["op"] == "history":
+		hash, resp, err = functions.GetHashHistory(req)
+	case qs["op"] == "get":
+		hash, resp, err = functions.GetHashByID(req)
+	case qs["op"] == "stats":
+		hash, resp, err = functions.GetCacheStats(req)
+	default:
+		hash, resp, err = functions.GetLatestHashFilePairAndZip(req)
+	}
+	if err != nil {
+		fmt.Printf("Error serving GET request: %s\n", err.Error())
+		return &APIResponse{
+			StatusCode: http.StatusInternalServerError,
+			Body:       "Error serving request: " + err.Error(),
+		}
+	}
+
+	if resp.StatusCode >= 400 {
+		fmt.Printf("Access key %s request failed with status %d: %s\n", keyID, resp.StatusCode, resp.Body)
+		return FromFunctionsResponse(resp)
+	}
+
+	if hash != "" {
+		fmt.Printf("Access key %s served request for hash %s\n", keyID, hash)
+	} else {
+		fmt.Printf("Access key %s served request\n", keyID)
+	}
+
+	return FromFunctionsResponse(resp)
+}
+
+// handlePost mints a new access key. It is protected by a separate admin
+// token (not an access key) read from the ADMIN_TOKEN environment
+// variable, since minting keys is an administrative operation.
+func handlePost(req *functions.Request) *APIResponse {
+	adminToken := os.Getenv("ADMIN_TOKEN")
+	if adminToken == "" {
+		return &APIResponse{
+			StatusCode: http.StatusInternalServerError,
+			Body:       "Admin token is not configured",
+		}
+	}
+	if subtle.ConstantTimeCompare([]byte(req.HeaderValue("X-Admin-Token")), []byte(adminToken)) != 1 {
+		return &APIResponse{
+			StatusCode: http.StatusForbidden,
+			Body:       "Invalid admin token",
+		}
+	}
+
+	var payload struct {
+		TTLSeconds         int `json:"ttlSeconds"`
+		RateLimitPerMinute int `json:"rateLimitPerMinute"`
+	}
+	if req.Body != "" {
+		if err := json.Unmarshal([]byte(req.Body), &payload); err != nil {
+			return &APIResponse{
+				StatusCode: http.StatusBadRequest,
+				Body:       "Invalid request body: " + err.Error(),
+			}
+		}
+	}
+
+	keyID, secret, err := accesskey.Generate(time.Duration(payload.TTLSeconds)*time.Second, payload.RateLimitPerMinute)
+	if err != nil {
+		fmt.Printf("Error generating access key: %s\n", err.Error())
+		return &APIResponse{
+			StatusCode: http.StatusInternalServerError,
+			Body:       "Error generating access key: " + err.Error(),
+		}
+	}
+
+	body, err := json.Marshal(map[string]string{"keyId": keyID, "secret": secret})
+	if err != nil {
+		return &APIResponse{
+			StatusCode: http.StatusInternalServerError,
+			Body:       "Error encoding access key response: " + err.Error(),
+		}
+	}
+
+	return &APIResponse{
+		StatusCode: http.StatusCreated,
+		Body:       string(body),
+		Headers: map[string]string{
+			"Content-Type": "application/json",
+		},
 	}
 }
@@ -0,0 +1,41 @@
+package handler
+
+import (
+	"fmt"
+	"net/http"
+
+	"go_lambdas/pkg/accesskey"
+	"go_lambdas/pkg/functions"
+)
+
+// StreamHandler is the Lambda Function URL entrypoint for RESPONSE_MODE=stream,
+// started via lambdaurl.Start in bin/stream/main.go. Unlike Handler/FnHandler/
+// SCFHandler it writes the zip body straight to w rather than returning a
+// buffered Response, so it bypasses the route()/APIResponse plumbing those
+// share.
+func StreamHandler(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	req, err := requestFromFn(r)
+	if err != nil {
+		http.Error(w, "Invalid request: "+err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	keyID, err := accesskey.Verify(req)
+	if err != nil {
+		fmt.Printf("Access key verification failed: %s\n", err.Error())
+		http.Error(w, "Unauthorized: "+err.Error(), http.StatusUnauthorized)
+		return
+	}
+
+	hash, err := functions.GetLatestHashFilePairAndZipStream(req, w)
+	if err != nil {
+		fmt.Printf("Access key %s failed to stream zip: %s\n", keyID, err.Error())
+		return
+	}
+	fmt.Printf("Access key %s served streamed request for hash %s\n", keyID, hash)
+}
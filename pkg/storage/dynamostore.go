@@ -0,0 +1,219 @@
+package storage
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/session"
+	"github.com/aws/aws-sdk-go/service/dynamodb"
+	"github.com/aws/aws-sdk-go/service/dynamodb/dynamodbattribute"
+)
+
+// partitionKeyValue is the fixed partition key every item in the
+// file-script table is written under; timestamp is the sort key, so the
+// latest record is a Query with ScanIndexForward=false and Limit=1 instead
+// of a full-table Scan.
+const partitionKeyValue = "file-script"
+
+// hashIndexName is a global secondary index with hash as its partition key,
+// used by GetByHash to look up a record directly instead of scanning.
+const hashIndexName = "hash-index"
+
+// dynamoItem mirrors the record shape stored in the file-script table. PK is
+// always partitionKeyValue; Hash is also the partition key of hashIndexName.
+type dynamoItem struct {
+	PK        string `json:"pk"`
+	Hash      string `json:"hash"`
+	Filename  string `json:"filename"`
+	Timestamp string `json:"timestamp"`
+}
+
+// dynamoMetadataStore implements MetadataStore with an indexed Query against
+// a fixed partition key, rather than a full-table Scan.
+type dynamoMetadataStore struct {
+	svc *dynamodb.DynamoDB
+}
+
+func newDynamoMetadataStore() (*dynamoMetadataStore, error) {
+	sess, err := session.NewSession()
+	if err != nil {
+		return nil, fmt.Errorf("failed to create AWS session: %w", err)
+	}
+	return &dynamoMetadataStore{svc: dynamodb.New(sess)}, nil
+}
+
+func (st *dynamoMetadataStore) LatestRecord(table string) (string, string, error) {
+	result, err := st.svc.Query(&dynamodb.QueryInput{
+		TableName:              aws.String(table),
+		KeyConditionExpression: aws.String("pk = :pk"),
+		ExpressionAttributeValues: map[string]*dynamodb.AttributeValue{
+			":pk": {S: aws.String(partitionKeyValue)},
+		},
+		ScanIndexForward: aws.Bool(false),
+		Limit:            aws.Int64(1),
+	})
+	if err != nil {
+		return "", "", fmt.Errorf("failed to query DynamoDB table: %w", err)
+	}
+	if len(result.Items) == 0 {
+		return "", "", fmt.Errorf("no items found in DynamoDB table")
+	}
+
+	var item dynamoItem
+	if err := dynamodbattribute.UnmarshalMap(result.Items[0], &item); err != nil {
+		return "", "", fmt.Errorf("failed to unmarshal DynamoDB query result: %w", err)
+	}
+	return item.Hash, item.Filename, nil
+}
+
+func (st *dynamoMetadataStore) ListHashHistory(table string, limit int, cursor string) ([]Record, string, error) {
+	if limit <= 0 {
+		limit = 20
+	}
+
+	input := &dynamodb.QueryInput{
+		TableName:              aws.String(table),
+		KeyConditionExpression: aws.String("pk = :pk"),
+		ExpressionAttributeValues: map[string]*dynamodb.AttributeValue{
+			":pk": {S: aws.String(partitionKeyValue)},
+		},
+		ScanIndexForward: aws.Bool(false),
+		Limit:            aws.Int64(int64(limit)),
+	}
+	if cursor != "" {
+		startKey, err := decodeCursor(cursor)
+		if err != nil {
+			return nil, "", fmt.Errorf("invalid cursor: %w", err)
+		}
+		input.ExclusiveStartKey = startKey
+	}
+
+	result, err := st.svc.Query(input)
+	if err != nil {
+		return nil, "", fmt.Errorf("failed to query DynamoDB table: %w", err)
+	}
+
+	var items []dynamoItem
+	if err := dynamodbattribute.UnmarshalListOfMaps(result.Items, &items); err != nil {
+		return nil, "", fmt.Errorf("failed to unmarshal DynamoDB query result: %w", err)
+	}
+
+	records := make([]Record, len(items))
+	for i, item := range items {
+		records[i] = Record{Hash: item.Hash, Filename: item.Filename, Timestamp: item.Timestamp}
+	}
+
+	nextCursor := ""
+	if len(result.LastEvaluatedKey) > 0 {
+		nextCursor, err = encodeCursor(result.LastEvaluatedKey)
+		if err != nil {
+			return nil, "", fmt.Errorf("failed to encode next cursor: %w", err)
+		}
+	}
+	return records, nextCursor, nil
+}
+
+func (st *dynamoMetadataStore) GetByHash(table, hash string) (string, string, error) {
+	result, err := st.svc.Query(&dynamodb.QueryInput{
+		TableName:              aws.String(table),
+		IndexName:              aws.String(hashIndexName),
+		KeyConditionExpression: aws.String("hash = :hash"),
+		ExpressionAttributeValues: map[string]*dynamodb.AttributeValue{
+			":hash": {S: aws.String(hash)},
+		},
+		Limit: aws.Int64(1),
+	})
+	if err != nil {
+		return "", "", fmt.Errorf("failed to query %s: %w", hashIndexName, err)
+	}
+	if len(result.Items) == 0 {
+		return "", "", fmt.Errorf("no record found for hash %s", hash)
+	}
+
+	var item dynamoItem
+	if err := dynamodbattribute.UnmarshalMap(result.Items[0], &item); err != nil {
+		return "", "", fmt.Errorf("failed to unmarshal DynamoDB query result: %w", err)
+	}
+	return item.Filename, item.Timestamp, nil
+}
+
+// MigrateLegacyItems scans table for items written under the old schema
+// (hash/filename/timestamp only, no pk attribute) and rewrites them with
+// pk=partitionKeyValue so LatestRecord/ListHashHistory, which only Query by
+// pk, can find them. It is meant to be run once, out of band, before
+// switching a table over to the new access pattern.
+func (st *dynamoMetadataStore) MigrateLegacyItems(table string) (int, error) {
+	input := &dynamodb.ScanInput{
+		TableName:        aws.String(table),
+		FilterExpression: aws.String("attribute_not_exists(pk)"),
+	}
+
+	var legacy []dynamoItem
+	for {
+		result, err := st.svc.Scan(input)
+		if err != nil {
+			return 0, fmt.Errorf("failed to scan for legacy items: %w", err)
+		}
+		var page []dynamoItem
+		if err := dynamodbattribute.UnmarshalListOfMaps(result.Items, &page); err != nil {
+			return 0, fmt.Errorf("failed to unmarshal legacy items: %w", err)
+		}
+		legacy = append(legacy, page...)
+		if len(result.LastEvaluatedKey) == 0 {
+			break
+		}
+		input.ExclusiveStartKey = result.LastEvaluatedKey
+	}
+
+	migrated := 0
+	for _, item := range legacy {
+		item.PK = partitionKeyValue
+		av, err := dynamodbattribute.MarshalMap(item)
+		if err != nil {
+			return migrated, fmt.Errorf("failed to marshal migrated item for hash %s: %w", item.Hash, err)
+		}
+		if _, err := st.svc.PutItem(&dynamodb.PutItemInput{TableName: aws.String(table), Item: av}); err != nil {
+			return migrated, fmt.Errorf("failed to write migrated item for hash %s: %w", item.Hash, err)
+		}
+		migrated++
+	}
+	return migrated, nil
+}
+
+// MigrateLegacyDynamoItems runs MigrateLegacyItems against the DynamoDB
+// metadata store. It's exposed at package level, rather than only on the
+// unexported *dynamoMetadataStore type, so callers outside this package
+// (cmd/migrate) can actually invoke the one-shot migration without a
+// MetadataStore interface method or a way to obtain the concrete type.
+func MigrateLegacyDynamoItems(table string) (int, error) {
+	store, err := newDynamoMetadataStore()
+	if err != nil {
+		return 0, err
+	}
+	return store.MigrateLegacyItems(table)
+}
+
+// decodeCursor/encodeCursor round-trip a DynamoDB LastEvaluatedKey through an
+// opaque base64-encoded JSON string, so callers don't need to know anything
+// about DynamoDB's key shape to page through results.
+func decodeCursor(cursor string) (map[string]*dynamodb.AttributeValue, error) {
+	raw, err := base64.URLEncoding.DecodeString(cursor)
+	if err != nil {
+		return nil, err
+	}
+	var key map[string]*dynamodb.AttributeValue
+	if err := json.Unmarshal(raw, &key); err != nil {
+		return nil, err
+	}
+	return key, nil
+}
+
+func encodeCursor(key map[string]*dynamodb.AttributeValue) (string, error) {
+	raw, err := json.Marshal(key)
+	if err != nil {
+		return "", err
+	}
+	return base64.URLEncoding.EncodeToString(raw), nil
+}
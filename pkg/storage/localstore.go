@@ -0,0 +1,118 @@
+package storage
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"sort"
+	"strconv"
+	"time"
+)
+
+const defaultLocalKVPath = "./data/file-script.json"
+
+// localMetadataStore implements MetadataStore by reading a JSON file of
+// dynamoItem-shaped records from disk. It exists for non-AWS deployments
+// (e.g. Fn Project or Tencent SCF running outside of AWS) that have no
+// DynamoDB table to query.
+type localMetadataStore struct {
+	path string
+}
+
+func newLocalMetadataStore() (*localMetadataStore, error) {
+	path := os.Getenv("LOCAL_KV_PATH")
+	if path == "" {
+		path = defaultLocalKVPath
+	}
+	return &localMetadataStore{path: path}, nil
+}
+
+// sortedItems reads the local KV file and returns its items sorted most
+// recent first, mirroring the order a Query with ScanIndexForward=false
+// would return from DynamoDB.
+func (st *localMetadataStore) sortedItems() ([]dynamoItem, error) {
+	raw, err := os.ReadFile(st.path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read local KV store %s: %w", st.path, err)
+	}
+
+	var items []dynamoItem
+	if err := json.Unmarshal(raw, &items); err != nil {
+		return nil, fmt.Errorf("failed to parse local KV store %s: %w", st.path, err)
+	}
+
+	sort.Slice(items, func(i, j int) bool {
+		ti, _ := time.Parse(time.RFC3339, items[i].Timestamp)
+		tj, _ := time.Parse(time.RFC3339, items[j].Timestamp)
+		return ti.After(tj)
+	})
+	return items, nil
+}
+
+// LatestRecord ignores its table argument: the local KV store is a single
+// JSON file rather than a collection of named tables.
+func (st *localMetadataStore) LatestRecord(table string) (string, string, error) {
+	items, err := st.sortedItems()
+	if err != nil {
+		return "", "", err
+	}
+	if len(items) == 0 {
+		return "", "", fmt.Errorf("no items found in local KV store %s", st.path)
+	}
+	return items[0].Hash, items[0].Filename, nil
+}
+
+// ListHashHistory pages through the sorted local KV file, using the page's
+// starting index (base-10, stringified) as the cursor rather than an opaque
+// DynamoDB key, since there is no real pagination token for a flat file.
+func (st *localMetadataStore) ListHashHistory(table string, limit int, cursor string) ([]Record, string, error) {
+	if limit <= 0 {
+		limit = 20
+	}
+
+	items, err := st.sortedItems()
+	if err != nil {
+		return nil, "", err
+	}
+
+	start := 0
+	if cursor != "" {
+		start, err = strconv.Atoi(cursor)
+		if err != nil || start < 0 {
+			return nil, "", fmt.Errorf("invalid cursor %q", cursor)
+		}
+	}
+	if start >= len(items) {
+		return nil, "", nil
+	}
+
+	end := start + limit
+	if end > len(items) {
+		end = len(items)
+	}
+
+	records := make([]Record, 0, end-start)
+	for _, item := range items[start:end] {
+		records = append(records, Record{Hash: item.Hash, Filename: item.Filename, Timestamp: item.Timestamp})
+	}
+
+	nextCursor := ""
+	if end < len(items) {
+		nextCursor = strconv.Itoa(end)
+	}
+	return records, nextCursor, nil
+}
+
+// GetByHash ignores its table argument, same as LatestRecord.
+func (st *localMetadataStore) GetByHash(table, hash string) (string, string, error) {
+	items, err := st.sortedItems()
+	if err != nil {
+		return "", "", err
+	}
+	for _, item := range items {
+		if item.Hash == hash {
+			return item.Filename, item.Timestamp, nil
+		}
+	}
+	return "", "", fmt.Errorf("no record found for hash %s", hash)
+}
@@ -0,0 +1,139 @@
+package storage
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/credentials"
+	"github.com/aws/aws-sdk-go/aws/session"
+	"github.com/aws/aws-sdk-go/service/s3"
+)
+
+// s3Store implements ObjectStore on top of the AWS SDK's S3 client. It also
+// backs the "minio" driver, since MinIO speaks the S3 API: pointing the same
+// client at a custom endpoint with path-style addressing is the standard way
+// to talk to an S3-compatible store without pulling in a second SDK.
+type s3Store struct {
+	svc *s3.S3
+}
+
+func newS3Store() (*s3Store, error) {
+	sess, err := session.NewSession()
+	if err != nil {
+		return nil, fmt.Errorf("failed to create AWS session: %w", err)
+	}
+	return &s3Store{svc: s3.New(sess)}, nil
+}
+
+// newMinioStore builds an s3Store pointed at a MinIO (or other S3-compatible)
+// endpoint, configured via S3_ENDPOINT, MINIO_ACCESS_KEY, and
+// MINIO_SECRET_KEY. Path-style addressing is always forced on, since MinIO
+// expects path-style requests (bucket.endpoint virtual-hosting is an
+// S3-specific convention).
+func newMinioStore() (*s3Store, error) {
+	endpoint := os.Getenv("S3_ENDPOINT")
+	if endpoint == "" {
+		return nil, fmt.Errorf("S3_ENDPOINT is required for the minio object store driver")
+	}
+
+	cfg := aws.NewConfig().
+		WithEndpoint(endpoint).
+		WithS3ForcePathStyle(true)
+	if accessKey, secretKey := os.Getenv("MINIO_ACCESS_KEY"), os.Getenv("MINIO_SECRET_KEY"); accessKey != "" {
+		cfg = cfg.WithCredentials(credentials.NewStaticCredentials(accessKey, secretKey, ""))
+	}
+	if region := os.Getenv("S3_REGION"); region != "" {
+		cfg = cfg.WithRegion(region)
+	}
+
+	sess, err := session.NewSession(cfg)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create MinIO session: %w", err)
+	}
+	return &s3Store{svc: s3.New(sess)}, nil
+}
+
+func (st *s3Store) Head(bucket, key string) (int64, string, error) {
+	head, err := st.svc.HeadObject(&s3.HeadObjectInput{
+		Bucket: aws.String(bucket),
+		Key:    aws.String(key),
+	})
+	if err != nil {
+		return 0, "", fmt.Errorf("failed to head object %s: %w", key, err)
+	}
+	if head.ContentLength == nil || head.ETag == nil {
+		return 0, "", fmt.Errorf("object %s is missing size or ETag", key)
+	}
+	return *head.ContentLength, *head.ETag, nil
+}
+
+func (st *s3Store) GetRange(bucket, key string, start, end int64) ([]byte, error) {
+	result, err := st.svc.GetObject(&s3.GetObjectInput{
+		Bucket: aws.String(bucket),
+		Key:    aws.String(key),
+		Range:  aws.String(fmt.Sprintf("bytes=%d-%d", start, end)),
+	})
+	if err != nil {
+		return nil, err
+	}
+	defer result.Body.Close()
+
+	buf := new(bytes.Buffer)
+	if _, err := io.Copy(buf, result.Body); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+func (st *s3Store) Get(bucket, key string) ([]byte, error) {
+	result, err := st.svc.GetObject(&s3.GetObjectInput{
+		Bucket: aws.String(bucket),
+		Key:    aws.String(key),
+	})
+	if err != nil {
+		return nil, err
+	}
+	defer result.Body.Close()
+
+	buf := new(bytes.Buffer)
+	if _, err := io.Copy(buf, result.Body); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+func (st *s3Store) PresignGetURL(bucket, key string, ttl time.Duration) (string, error) {
+	request, _ := st.svc.GetObjectRequest(&s3.GetObjectInput{
+		Bucket: aws.String(bucket),
+		Key:    aws.String(key),
+	})
+	url, err := request.Presign(ttl)
+	if err != nil {
+		return "", fmt.Errorf("failed to presign %s: %w", key, err)
+	}
+	return url, nil
+}
+
+func (st *s3Store) ListKeysWithPrefix(bucket, prefix string) ([]string, error) {
+	listOutput, err := st.svc.ListObjectsV2(&s3.ListObjectsV2Input{
+		Bucket: aws.String(bucket),
+		Prefix: aws.String(prefix),
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to list objects under %s: %w", prefix, err)
+	}
+
+	keys := make([]string, 0, len(listOutput.Contents))
+	for _, obj := range listOutput.Contents {
+		if obj.Key == nil || strings.HasSuffix(*obj.Key, "/") {
+			continue
+		}
+		keys = append(keys, *obj.Key)
+	}
+	return keys, nil
+}
@@ -0,0 +1,89 @@
+// Package storage abstracts the object store and metadata store that
+// functions.GetLatestHashFilePairAndZip and its siblings depend on, so the
+// same business logic can run against AWS (S3 + DynamoDB) or a non-AWS
+// deployment (a MinIO bucket + a local KV file) without the pkg/functions
+// package importing any provider-specific SDK.
+//
+// The concrete driver is chosen at process start via environment variables,
+// mirroring the RESPONSE_MODE-style configuration used elsewhere in this
+// repo rather than requiring callers to wire up a driver explicitly.
+package storage
+
+import (
+	"fmt"
+	"os"
+	"time"
+)
+
+// ObjectStore is the subset of S3-like operations the zip-serving code
+// needs: locating the zip for a hash, reading byte ranges out of it to
+// parse the central directory and individual entries, and fetching the
+// whole object for the legacy base64 download path.
+type ObjectStore interface {
+	// Head returns the size and a change-detection token (an ETag for S3
+	// and MinIO) for the object at key.
+	Head(bucket, key string) (size int64, etag string, err error)
+	// GetRange fetches the inclusive byte range [start, end] of the object.
+	GetRange(bucket, key string, start, end int64) ([]byte, error)
+	// Get fetches the whole object.
+	Get(bucket, key string) ([]byte, error)
+	// ListKeysWithPrefix lists object keys under prefix.
+	ListKeysWithPrefix(bucket, prefix string) ([]string, error)
+	// PresignGetURL returns a time-limited URL a client can use to fetch the
+	// object directly, bypassing the FaaS response body entirely.
+	PresignGetURL(bucket, key string, ttl time.Duration) (string, error)
+}
+
+// Record is a single hash/filename/timestamp entry from a MetadataStore,
+// returned by ListHashHistory for history/pagination routes.
+type Record struct {
+	Hash      string `json:"hash"`
+	Filename  string `json:"filename"`
+	Timestamp string `json:"timestamp"`
+}
+
+// MetadataStore is the subset of DynamoDB-like operations needed to find the
+// latest hash/filename pair for a table, page through its history, and look
+// up a specific hash directly.
+type MetadataStore interface {
+	// LatestRecord returns the hash and filename of the most recently
+	// written record in table.
+	LatestRecord(table string) (hash, filename string, err error)
+	// ListHashHistory returns up to limit records in table, most recent
+	// first, starting after cursor (the opaque string previously returned
+	// as nextCursor, or "" for the first page). nextCursor is "" once
+	// there are no further pages.
+	ListHashHistory(table string, limit int, cursor string) (records []Record, nextCursor string, err error)
+	// GetByHash looks up a record by hash directly, rather than by recency.
+	GetByHash(table, hash string) (filename, timestamp string, err error)
+}
+
+// NewObjectStore returns the ObjectStore driver selected by the
+// OBJECT_STORE_DRIVER environment variable: "s3" (the default, real AWS S3)
+// or "minio" (an S3-compatible endpoint, configured via S3_ENDPOINT and the
+// MINIO_ACCESS_KEY/MINIO_SECRET_KEY pair).
+func NewObjectStore() (ObjectStore, error) {
+	switch driver := os.Getenv("OBJECT_STORE_DRIVER"); driver {
+	case "", "s3":
+		return newS3Store()
+	case "minio":
+		return newMinioStore()
+	default:
+		return nil, fmt.Errorf("unknown OBJECT_STORE_DRIVER %q", driver)
+	}
+}
+
+// NewMetadataStore returns the MetadataStore driver selected by the
+// METADATA_STORE_DRIVER environment variable: "dynamodb" (the default) or
+// "local" (a JSON file on disk, for non-AWS deployments without a DynamoDB
+// table available).
+func NewMetadataStore() (MetadataStore, error) {
+	switch driver := os.Getenv("METADATA_STORE_DRIVER"); driver {
+	case "", "dynamodb":
+		return newDynamoMetadataStore()
+	case "local":
+		return newLocalMetadataStore()
+	default:
+		return nil, fmt.Errorf("unknown METADATA_STORE_DRIVER %q", driver)
+	}
+}